@@ -1,499 +1,607 @@
 package main
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
+	"errors"
+	apigen "github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/apigen/runtime"
 	"net/http"
+	"net/url"
 	"strconv"
-	"strings"
+	"time"
 )
 
 // auto-generated file: do not edit!
 
-type httpResult struct {
-	Error    string      `json:"error"`
-	Response interface{} `json:"response"`
-}
+// Не скомпилируется с версией apigen/runtime, в которой нет API этого файла
+const _ = apigen.APIVersion8
 
-func marshal(res httpResult) []byte {
-	resMap := make(map[string]interface{})
-	resMap["error"] = res.Error
-	if res.Response != nil {
-		resMap["response"] = res.Response
+func (h *MyApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/user/create":
+		h.wrapperCreate(w, r, nil)
+	case "/user/profile":
+		h.wrapperProfile(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
 	}
-	resultStr, _ := json.Marshal(resMap)
-	return resultStr
 }
 
-func writeResponse(w http.ResponseWriter, response []byte) {
-	_, _ = w.Write(response)
-}
-
-func contains(arr []string, item string) bool {
-	for _, i := range arr {
-		if item == i {
-			return true
-		}
+func (h *MyApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
 	}
-	return false
+	return apigen.DefaultAuthenticator
 }
 
-func printSlice(s []string) string {
-	return "[" + strings.Join(s, ", ") + "]"
+func (h *MyApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
 }
 
-func validateMinMaxInt(value int, valueName, min, max string) error {
-	if min != "" {
-		minInt, err := strconv.Atoi(min)
-		if err != nil {
-			return err
-		}
-		if value < minInt {
-			return fmt.Errorf(valueName + " must be >= " + min)
-		}
+func (h *MyApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
 	}
+	return apigen.DefaultLogger
+}
 
-	if max != "" {
-		maxInt, err := strconv.Atoi(max)
-		if err != nil {
-			return err
-		}
-		if value > maxInt {
-			return fmt.Errorf(valueName + " must be <= " + max)
-		}
+func (h *MyApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
 	}
-
-	return nil
+	return apigen.DefaultMetrics
 }
 
-func validateMinMaxStr(value, valueName, min, max string) error {
-	if min != "" {
-		minInt, err := strconv.Atoi(min)
-		if err != nil {
-			return err
-		}
-		if len(value) < minInt {
-			return fmt.Errorf(valueName + " len must be >= " + min)
-		}
+func (h *MyApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
 	}
-
-	if max != "" {
-		maxInt, err := strconv.Atoi(max)
-		if err != nil {
-			return err
-		}
-		if len(value) > maxInt {
-			return fmt.Errorf(valueName + " len must be <= " + max)
-		}
-	}
-
-	return nil
+	return apigen.DefaultLimiter
 }
 
-func (h *OtherApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	switch r.URL.Path {
-	case "/user/create":
-		h.wrapperCreate(w, r)
-	default:
-		w.WriteHeader(http.StatusNotFound)
-		writeResponse(w, marshal(httpResult{Error: "unknown method"}))
+func (h *MyApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
 	}
+	return apigen.DefaultCache
 }
 
-func (h *OtherApi) wrapperCreate(w http.ResponseWriter, r *http.Request) {
+func (h *MyApi) wrapperCreate(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/user/create", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
 	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "MyApi.Create", p)
+		}
+	}()
 
-	if r.Method != "POST" {
-		w.WriteHeader(http.StatusNotAcceptable)
-		writeResponse(w, marshal(httpResult{Error: "bad method"}))
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
-	if r.Header.Get("X-Auth") != "100500" {
-		w.WriteHeader(http.StatusForbidden)
-		writeResponse(w, marshal(httpResult{Error: "unauthorized"}))
+	switch r.Method {
+	case "POST":
+	default:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("bad method"))
 		return
 	}
 
-	p0, err := validateAndBuildOtherCreateParams(r)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		writeResponse(w, marshal(httpResult{Error: err.Error()}))
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
 		return
 	}
 
-	res, err := h.Create(
-		ctx,
-		*p0,
-	)
-
+	principal, err := h.getAuthenticator().Authenticate(r)
 	if err != nil {
-		apiErr, ok := err.(ApiError)
-		if ok {
-			w.WriteHeader(apiErr.HTTPStatus)
-		} else {
-			w.WriteHeader(http.StatusInternalServerError)
+		status := http.StatusForbidden
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
 		}
-		writeResponse(w, marshal(httpResult{Error: err.Error()}))
+		encoder.EncodeError(w, r, status, err)
 		return
 	}
+	ctx = apigen.ContextWithPrincipal(ctx, principal)
 
-	w.WriteHeader(http.StatusOK)
-	writeResponse(w, marshal(httpResult{Response: res}))
-}
-
-func (h *MyApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	switch r.URL.Path {
-	case "/user/create":
-		h.wrapperCreate(w, r)
-	case "/user/profile":
-		h.wrapperProfile(w, r)
-	default:
-		w.WriteHeader(http.StatusNotFound)
-		writeResponse(w, marshal(httpResult{Error: "unknown method"}))
-	}
+	r = r.WithContext(ctx)
+	h.handleCreate(w, r, encoder, pathParams)
 }
 
-func (h *MyApi) wrapperProfile(w http.ResponseWriter, r *http.Request) {
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *MyApi) handleCreate(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
 	ctx := r.Context()
-
-	p0, err := validateAndBuildProfileParams(r)
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/user/create")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildCreateParams(ctx, h, params, false)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		writeResponse(w, marshal(httpResult{Error: err.Error()}))
+		h.getMetrics().ObserveValidationFailure("/user/create")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
-	res, err := h.Profile(
+	res, err := h.Create(
 		ctx,
 		*p0,
 	)
 
 	if err != nil {
-		apiErr, ok := err.(ApiError)
-		if ok {
-			w.WriteHeader(apiErr.HTTPStatus)
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
 		} else {
-			w.WriteHeader(http.StatusInternalServerError)
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "MyApi.Create", Status: status, Err: err})
 		}
-		writeResponse(w, marshal(httpResult{Error: err.Error()}))
+		encoder.EncodeError(w, r, status, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	writeResponse(w, marshal(httpResult{Response: res}))
+	encoder.EncodeResponse(w, r, res)
 }
 
-func (h *MyApi) wrapperCreate(w http.ResponseWriter, r *http.Request) {
+func (h *MyApi) wrapperProfile(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/user/profile", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
 	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "MyApi.Profile", p)
+		}
+	}()
 
-	if r.Method != "POST" {
-		w.WriteHeader(http.StatusNotAcceptable)
-		writeResponse(w, marshal(httpResult{Error: "bad method"}))
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 
-	if r.Header.Get("X-Auth") != "100500" {
-		w.WriteHeader(http.StatusForbidden)
-		writeResponse(w, marshal(httpResult{Error: "unauthorized"}))
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
 		return
 	}
 
-	p0, err := validateAndBuildCreateParams(r)
+	r = r.WithContext(ctx)
+	h.handleProfile(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *MyApi) handleProfile(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		writeResponse(w, marshal(httpResult{Error: err.Error()}))
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/user/profile")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildProfileParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/user/profile")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
-	res, err := h.Create(
+	res, err := h.Profile(
 		ctx,
 		*p0,
 	)
 
 	if err != nil {
-		apiErr, ok := err.(ApiError)
-		if ok {
-			w.WriteHeader(apiErr.HTTPStatus)
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
 		} else {
-			w.WriteHeader(http.StatusInternalServerError)
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "MyApi.Profile", Status: status, Err: err})
 		}
-		writeResponse(w, marshal(httpResult{Error: err.Error()}))
+		encoder.EncodeError(w, r, status, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	writeResponse(w, marshal(httpResult{Response: res}))
+	encoder.EncodeResponse(w, r, res)
 }
 
-func validateAndBuildCreateParams(r *http.Request) (*CreateParams, error) {
-	res := CreateParams{}
-
-	var paramName string
-	var paramValue string
-	var required bool
-	var defaultValue string
-	var enum []string
-
-	var err error
-
-	paramName = strings.ToLower("Age")
-
-	paramValue = r.FormValue(paramName)
-	required = false
-
-	if required && paramValue == "" {
-		return nil, fmt.Errorf(paramName + " must me not empty")
-	}
-
-	defaultValue = ""
-	if paramValue == "" && defaultValue != "" {
-		paramValue = defaultValue
+func (h *OtherApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/user/create":
+		h.wrapperCreate(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
 	}
+}
 
-	enum = make([]string, 0)
-	if len(enum) > 0 && !contains(enum, paramValue) {
-		return nil, fmt.Errorf(paramName + " must be one of " + printSlice(enum))
+func (h *OtherApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
 	}
+	return apigen.DefaultAuthenticator
+}
 
-	intAgeVal, err := strconv.Atoi(paramValue)
-	if err != nil {
-		return nil, fmt.Errorf(paramName + " must be int")
-	}
-	if err = validateMinMaxInt(intAgeVal, paramName, "0", "128"); err != nil {
-		return nil, err
+func (h *OtherApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
 	}
-	res.Age = intAgeVal
-
-	paramName = strings.ToLower("Login")
-
-	paramValue = r.FormValue(paramName)
-	required = true
+	return apigen.DefaultEncoder
+}
 
-	if required && paramValue == "" {
-		return nil, fmt.Errorf(paramName + " must me not empty")
+func (h *OtherApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
 	}
+	return apigen.DefaultLogger
+}
 
-	defaultValue = ""
-	if paramValue == "" && defaultValue != "" {
-		paramValue = defaultValue
+func (h *OtherApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
 	}
+	return apigen.DefaultMetrics
+}
 
-	enum = make([]string, 0)
-	if len(enum) > 0 && !contains(enum, paramValue) {
-		return nil, fmt.Errorf(paramName + " must be one of " + printSlice(enum))
+func (h *OtherApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
 	}
+	return apigen.DefaultLimiter
+}
 
-	if err = validateMinMaxStr(paramValue, paramName, "10", ""); err != nil {
-		return nil, err
+func (h *OtherApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
 	}
-	res.Login = paramValue
+	return apigen.DefaultCache
+}
 
-	paramName = "full_name"
+func (h *OtherApi) wrapperCreate(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/user/create", sw.Status(), time.Since(start))
+	}(time.Now())
 
-	paramValue = r.FormValue(paramName)
-	required = false
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "OtherApi.Create", p)
+		}
+	}()
 
-	if required && paramValue == "" {
-		return nil, fmt.Errorf(paramName + " must me not empty")
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
 	}
 
-	defaultValue = ""
-	if paramValue == "" && defaultValue != "" {
-		paramValue = defaultValue
+	switch r.Method {
+	case "POST":
+	default:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("bad method"))
+		return
 	}
 
-	enum = make([]string, 0)
-	if len(enum) > 0 && !contains(enum, paramValue) {
-		return nil, fmt.Errorf(paramName + " must be one of " + printSlice(enum))
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
 	}
 
-	if err = validateMinMaxStr(paramValue, paramName, "", ""); err != nil {
-		return nil, err
+	principal, err := h.getAuthenticator().Authenticate(r)
+	if err != nil {
+		status := http.StatusForbidden
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
 	}
-	res.Name = paramValue
-
-	paramName = strings.ToLower("Status")
+	ctx = apigen.ContextWithPrincipal(ctx, principal)
 
-	paramValue = r.FormValue(paramName)
-	required = false
+	r = r.WithContext(ctx)
+	h.handleCreate(w, r, encoder, pathParams)
+}
 
-	if required && paramValue == "" {
-		return nil, fmt.Errorf(paramName + " must me not empty")
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *OtherApi) handleCreate(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/user/create")
+		encoder.EncodeError(w, r, status, err)
+		return
 	}
-
-	defaultValue = "user"
-	if paramValue == "" && defaultValue != "" {
-		paramValue = defaultValue
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildOtherCreateParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/user/create")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
 	}
 
-	enum = make([]string, 0)
-	enum = append(enum, "user")
-	enum = append(enum, "moderator")
-	enum = append(enum, "admin")
-	if len(enum) > 0 && !contains(enum, paramValue) {
-		return nil, fmt.Errorf(paramName + " must be one of " + printSlice(enum))
-	}
+	res, err := h.Create(
+		ctx,
+		*p0,
+	)
 
-	if err = validateMinMaxStr(paramValue, paramName, "", ""); err != nil {
-		return nil, err
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "OtherApi.Create", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
 	}
-	res.Status = paramValue
 
-	return &res, nil
+	encoder.EncodeResponse(w, r, res)
 }
 
-func validateAndBuildOtherCreateParams(r *http.Request) (*OtherCreateParams, error) {
-	res := OtherCreateParams{}
+const minCreateParamsLogin int = 10
+const minCreateParamsAge int = 0
+const maxCreateParamsAge int = 128
 
-	var paramName string
-	var paramValue string
-	var required bool
-	var defaultValue string
-	var enum []string
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildCreateParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*CreateParams, error) {
+	res := CreateParams{}
 
+	var errs apigen.ValidationErrors
 	var err error
 
-	paramName = strings.ToLower("Class")
+	err = func() error {
+		paramValue := params.Get("login")
+		if paramValue == "" {
+			return apigen.FieldError{Param: "login", Rule: "required", Message: "login must me not empty"}
+		}
 
-	paramValue = r.FormValue(paramName)
-	required = false
+		LoginVal := paramValue
+		if len(LoginVal) < minCreateParamsLogin {
+			return apigen.FieldError{Param: "login", Rule: "min", Message: "login len must be >= 10"}
+		}
 
-	if required && paramValue == "" {
-		return nil, fmt.Errorf(paramName + " must me not empty")
+		res.Login = LoginVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
 	}
 
-	defaultValue = "warrior"
-	if paramValue == "" && defaultValue != "" {
-		paramValue = defaultValue
-	}
+	err = func() error {
+		paramValue := params.Get("full_name")
 
-	enum = make([]string, 0)
-	enum = append(enum, "warrior")
-	enum = append(enum, "sorcerer")
-	enum = append(enum, "rouge")
-	if len(enum) > 0 && !contains(enum, paramValue) {
-		return nil, fmt.Errorf(paramName + " must be one of " + printSlice(enum))
-	}
+		NameVal := paramValue
 
-	if err = validateMinMaxStr(paramValue, paramName, "", ""); err != nil {
-		return nil, err
+		res.Name = NameVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
 	}
-	res.Class = paramValue
 
-	paramName = strings.ToLower("Level")
+	err = func() error {
+		paramValue := params.Get("status")
+		if paramValue == "" {
+			paramValue = "user"
+		}
 
-	paramValue = r.FormValue(paramName)
-	required = false
+		StatusVal := paramValue
 
-	if required && paramValue == "" {
-		return nil, fmt.Errorf(paramName + " must me not empty")
-	}
+		switch StatusVal {
+		case "user", "moderator", "admin":
+		default:
+			return apigen.FieldError{Param: "status", Rule: "enum", Message: "status must be one of [user, moderator, admin]"}
+		}
 
-	defaultValue = ""
-	if paramValue == "" && defaultValue != "" {
-		paramValue = defaultValue
+		res.Status = StatusVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
 	}
 
-	enum = make([]string, 0)
-	if len(enum) > 0 && !contains(enum, paramValue) {
-		return nil, fmt.Errorf(paramName + " must be one of " + printSlice(enum))
-	}
+	err = func() error {
+		paramValue := params.Get("age")
 
-	intLevelVal, err := strconv.Atoi(paramValue)
+		AgeVal, err := strconv.Atoi(paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: "age", Rule: "type", Message: "age must be int"}
+		}
+		if AgeVal < minCreateParamsAge {
+			return apigen.FieldError{Param: "age", Rule: "min", Message: "age must be >= 0"}
+		}
+		if AgeVal > maxCreateParamsAge {
+			return apigen.FieldError{Param: "age", Rule: "max", Message: "age must be <= 128"}
+		}
+
+		res.Age = AgeVal
+		return nil
+	}()
 	if err != nil {
-		return nil, fmt.Errorf(paramName + " must be int")
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
 	}
-	if err = validateMinMaxInt(intLevelVal, paramName, "1", "50"); err != nil {
-		return nil, err
+
+	if len(errs) > 0 {
+		return nil, errs
 	}
-	res.Level = intLevelVal
+	return &res, nil
+}
 
-	paramName = "account_name"
+const minOtherCreateParamsUsername int = 3
+const minOtherCreateParamsLevel int = 1
+const maxOtherCreateParamsLevel int = 50
 
-	paramValue = r.FormValue(paramName)
-	required = false
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildOtherCreateParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*OtherCreateParams, error) {
+	res := OtherCreateParams{}
 
-	if required && paramValue == "" {
-		return nil, fmt.Errorf(paramName + " must me not empty")
-	}
+	var errs apigen.ValidationErrors
+	var err error
 
-	defaultValue = ""
-	if paramValue == "" && defaultValue != "" {
-		paramValue = defaultValue
-	}
+	err = func() error {
+		paramValue := params.Get("username")
+		if paramValue == "" {
+			return apigen.FieldError{Param: "username", Rule: "required", Message: "username must me not empty"}
+		}
 
-	enum = make([]string, 0)
-	if len(enum) > 0 && !contains(enum, paramValue) {
-		return nil, fmt.Errorf(paramName + " must be one of " + printSlice(enum))
-	}
+		UsernameVal := paramValue
+		if len(UsernameVal) < minOtherCreateParamsUsername {
+			return apigen.FieldError{Param: "username", Rule: "min", Message: "username len must be >= 3"}
+		}
 
-	if err = validateMinMaxStr(paramValue, paramName, "", ""); err != nil {
-		return nil, err
+		res.Username = UsernameVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
 	}
-	res.Name = paramValue
 
-	paramName = strings.ToLower("Username")
+	err = func() error {
+		paramValue := params.Get("account_name")
 
-	paramValue = r.FormValue(paramName)
-	required = true
+		NameVal := paramValue
 
-	if required && paramValue == "" {
-		return nil, fmt.Errorf(paramName + " must me not empty")
+		res.Name = NameVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
 	}
 
-	defaultValue = ""
-	if paramValue == "" && defaultValue != "" {
-		paramValue = defaultValue
-	}
+	err = func() error {
+		paramValue := params.Get("class")
+		if paramValue == "" {
+			paramValue = "warrior"
+		}
+
+		ClassVal := paramValue
 
-	enum = make([]string, 0)
-	if len(enum) > 0 && !contains(enum, paramValue) {
-		return nil, fmt.Errorf(paramName + " must be one of " + printSlice(enum))
+		switch ClassVal {
+		case "warrior", "sorcerer", "rouge":
+		default:
+			return apigen.FieldError{Param: "class", Rule: "enum", Message: "class must be one of [warrior, sorcerer, rouge]"}
+		}
+
+		res.Class = ClassVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
 	}
 
-	if err = validateMinMaxStr(paramValue, paramName, "3", ""); err != nil {
-		return nil, err
+	err = func() error {
+		paramValue := params.Get("level")
+
+		LevelVal, err := strconv.Atoi(paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: "level", Rule: "type", Message: "level must be int"}
+		}
+		if LevelVal < minOtherCreateParamsLevel {
+			return apigen.FieldError{Param: "level", Rule: "min", Message: "level must be >= 1"}
+		}
+		if LevelVal > maxOtherCreateParamsLevel {
+			return apigen.FieldError{Param: "level", Rule: "max", Message: "level must be <= 50"}
+		}
+
+		res.Level = LevelVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
 	}
-	res.Username = paramValue
 
+	if len(errs) > 0 {
+		return nil, errs
+	}
 	return &res, nil
 }
 
-func validateAndBuildProfileParams(r *http.Request) (*ProfileParams, error) {
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildProfileParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*ProfileParams, error) {
 	res := ProfileParams{}
 
-	var paramName string
-	var paramValue string
-	var required bool
-	var defaultValue string
-	var enum []string
-
+	var errs apigen.ValidationErrors
 	var err error
 
-	paramName = strings.ToLower("Login")
-
-	paramValue = r.FormValue(paramName)
-	required = true
-
-	if required && paramValue == "" {
-		return nil, fmt.Errorf(paramName + " must me not empty")
-	}
+	err = func() error {
+		paramValue := params.Get("login")
+		if paramValue == "" {
+			return apigen.FieldError{Param: "login", Rule: "required", Message: "login must me not empty"}
+		}
 
-	defaultValue = ""
-	if paramValue == "" && defaultValue != "" {
-		paramValue = defaultValue
-	}
+		LoginVal := paramValue
 
-	enum = make([]string, 0)
-	if len(enum) > 0 && !contains(enum, paramValue) {
-		return nil, fmt.Errorf(paramName + " must be one of " + printSlice(enum))
+		res.Login = LoginVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
 	}
 
-	if err = validateMinMaxStr(paramValue, paramName, "", ""); err != nil {
-		return nil, err
+	if len(errs) > 0 {
+		return nil, errs
 	}
-	res.Login = paramValue
-
 	return &res, nil
 }
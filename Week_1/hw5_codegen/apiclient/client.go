@@ -0,0 +1,212 @@
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// auto-generated file: do not edit!
+
+// Ошибка, которую вернул сервер: HTTP-статус и текст из поля "error" (для application/problem+json - из "detail").
+// Для методов с "errors": "all" в Errors - ошибки всех параметров
+type ApiError struct {
+	HTTPStatus int
+	Err        error
+	Errors     []FieldError
+	// Заголовок X-Request-ID ответа
+	RequestID string
+}
+
+func (ae ApiError) Error() string {
+	return ae.Err.Error()
+}
+
+type FieldError struct {
+	Param   string `json:"param"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type httpResult struct {
+	Error    string          `json:"error"`
+	Errors   []FieldError    `json:"errors"`
+	Response json.RawMessage `json:"response"`
+	// Поля ошибки application/problem+json
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+}
+
+func (result httpResult) message() string {
+	switch {
+	case result.Error != "":
+		return result.Error
+	case result.Detail != "":
+		return result.Detail
+	default:
+		return result.Title
+	}
+}
+
+// Отправляет запрос и раскладывает ответ в res: при envelope - поле "response" из {"error": ..., "response": ...},
+// иначе всё тело
+func doRequest(ctx context.Context, client *http.Client, authToken, method, endpoint string, params url.Values, res interface{}, envelope bool) error {
+	var req *http.Request
+	var err error
+	if method == http.MethodGet {
+		if len(params) > 0 {
+			endpoint += "?" + params.Encode()
+		}
+		req, err = http.NewRequestWithContext(ctx, method, endpoint, nil)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, method, endpoint, strings.NewReader(params.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if authToken != "" {
+		req.Header.Set("X-Auth", authToken)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusOK && !envelope {
+		if res == nil {
+			return nil
+		}
+		return json.Unmarshal(body, res)
+	}
+
+	result := httpResult{}
+	if err = json.Unmarshal(body, &result); err != nil {
+		return ApiError{HTTPStatus: resp.StatusCode, Err: fmt.Errorf("bad response: %s", resp.Status), RequestID: resp.Header.Get("X-Request-ID")}
+	}
+	if resp.StatusCode != http.StatusOK || result.Error != "" {
+		return ApiError{HTTPStatus: resp.StatusCode, Err: fmt.Errorf("%s", result.message()), Errors: result.Errors, RequestID: resp.Header.Get("X-Request-ID")}
+	}
+
+	if res == nil || len(result.Response) == 0 {
+		return nil
+	}
+	return json.Unmarshal(result.Response, res)
+}
+
+type CreateParams struct {
+	Login  string `apivalidator:"required,min=10"`
+	Name   string `apivalidator:"paramname=full_name"`
+	Status string `apivalidator:"enum=user|moderator|admin,default=user"`
+	Age    int    `apivalidator:"min=0,max=128"`
+}
+
+type NewUser struct {
+	ID uint64 `json:"id"`
+}
+
+type ProfileParams struct {
+	Login string `apivalidator:"required"`
+}
+
+type User struct {
+	ID       uint64 `json:"id"`
+	Login    string `json:"login"`
+	FullName string `json:"full_name"`
+	Status   int    `json:"status"`
+}
+
+type OtherCreateParams struct {
+	Username string `apivalidator:"required,min=3"`
+	Name     string `apivalidator:"paramname=account_name"`
+	Class    string `apivalidator:"enum=warrior|sorcerer|rouge,default=warrior"`
+	Level    int    `apivalidator:"min=1,max=50"`
+}
+
+type OtherUser struct {
+	ID       uint64 `json:"id"`
+	Login    string `json:"login"`
+	FullName string `json:"full_name"`
+	Level    int    `json:"level"`
+}
+
+type MyApiClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// Значение заголовка X-Auth, пустое - заголовок не отправляется
+	AuthToken string
+}
+
+func NewMyApiClient(baseURL, authToken string) *MyApiClient {
+	return &MyApiClient{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		AuthToken:  authToken,
+	}
+}
+
+func (c *MyApiClient) Create(ctx context.Context, in CreateParams) (*NewUser, error) {
+	params := url.Values{}
+	params.Set("login", in.Login)
+	params.Set("full_name", in.Name)
+	params.Set("status", in.Status)
+	params.Set("age", strconv.Itoa(in.Age))
+
+	var res *NewUser
+	err := doRequest(ctx, c.HTTPClient, c.AuthToken, "POST", c.BaseURL+"/user/create", params, &res, true)
+	return res, err
+}
+
+func (c *MyApiClient) Profile(ctx context.Context, in ProfileParams) (*User, error) {
+	params := url.Values{}
+	params.Set("login", in.Login)
+
+	var res *User
+	err := doRequest(ctx, c.HTTPClient, c.AuthToken, "GET", c.BaseURL+"/user/profile", params, &res, true)
+	return res, err
+}
+
+type OtherApiClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// Значение заголовка X-Auth, пустое - заголовок не отправляется
+	AuthToken string
+}
+
+func NewOtherApiClient(baseURL, authToken string) *OtherApiClient {
+	return &OtherApiClient{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		AuthToken:  authToken,
+	}
+}
+
+func (c *OtherApiClient) Create(ctx context.Context, in OtherCreateParams) (*OtherUser, error) {
+	params := url.Values{}
+	params.Set("username", in.Username)
+	params.Set("account_name", in.Name)
+	params.Set("class", in.Class)
+	params.Set("level", strconv.Itoa(in.Level))
+
+	var res *OtherUser
+	err := doRequest(ctx, c.HTTPClient, c.AuthToken, "POST", c.BaseURL+"/user/create", params, &res, true)
+	return res, err
+}
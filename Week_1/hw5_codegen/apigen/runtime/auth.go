@@ -0,0 +1,74 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Тот, от чьего имени выполняется запрос
+type Principal struct {
+	ID    string
+	Roles []string
+}
+
+// Есть ли у автора запроса хотя бы одна из ролей
+func (p *Principal) HasAnyRole(roles ...string) bool {
+	if p == nil {
+		return false
+	}
+	for _, role := range roles {
+		if contains(p.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// Проверяет запрос и возвращает его автора. Ошибка типа ApiError отдаётся клиенту со своим статусом,
+// остальные ошибки - со статусом 403.
+// Свою проверку (токен, сессию) обработчик получает в поле типа Authenticator - так её можно подменить
+// в тестах - или реализует Authenticate сам
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Проверяет, что в заголовке Header пришло значение Token, автору запроса выдаёт ID и роли Roles.
+// Token в Principal не попадает: ID видят ключи Limiter и Cache, логи и ответы методов
+type HeaderAuthenticator struct {
+	Header string
+	Token  string
+	ID     string
+	Roles  []string
+}
+
+func (a HeaderAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if r.Header.Get(a.Header) != a.Token {
+		return nil, fmt.Errorf("unauthorized")
+	}
+	return &Principal{ID: a.ID, Roles: a.Roles}, nil
+}
+
+// Проверка из исходного задания - заголовок X-Auth: 100500
+var DefaultAuthenticator Authenticator = HeaderAuthenticator{Header: "X-Auth", Token: "100500", ID: "x-auth"}
+
+type principalContextKey struct{}
+
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// Автор запроса для методов с "auth": true
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+func contains(arr []string, item string) bool {
+	for _, i := range arr {
+		if item == i {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package runtime
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Хранит ответы методов с "cache": {"memory": true}. Ключ - CacheKey: обработчик, метод, автор запроса и
+// проверенные параметры. Чтобы ответы были общими для нескольких процессов, обработчик реализует Get и Set
+// поверх внешнего хранилища, значение тогда придётся сериализовать самому
+type Cache interface {
+	Get(key string) (interface{}, bool)
+	Set(key string, value interface{}, ttl time.Duration)
+}
+
+// Один кеш в памяти процесса на все обработчики
+var DefaultCache Cache = &MemoryCache{}
+
+// TTL-кеш в памяти процесса. Устаревшая запись не возвращается сразу, а удаляется при очистке раз в sweepInterval
+type MemoryCache struct {
+	mu        sync.Mutex
+	items     map[string]cacheItem
+	lastSweep time.Time
+	// Для тестов, nil - time.Now
+	now func() time.Time
+}
+
+type cacheItem struct {
+	value   interface{}
+	expires time.Time
+}
+
+func (c *MemoryCache) Get(key string) (interface{}, bool) {
+	now := c.timeNow()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item, ok := c.items[key]
+	if !ok || !now.Before(item.expires) {
+		return nil, false
+	}
+	return item.value, true
+}
+
+func (c *MemoryCache) Set(key string, value interface{}, ttl time.Duration) {
+	now := c.timeNow()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.items == nil {
+		c.items = map[string]cacheItem{}
+		c.lastSweep = now
+	}
+	// устаревшие записи удаляются раз в sweepInterval, как корзины MemoryLimiter
+	if now.Sub(c.lastSweep) > sweepInterval {
+		for k, item := range c.items {
+			if !now.Before(item.expires) {
+				delete(c.items, k)
+			}
+		}
+		c.lastSweep = now
+	}
+	c.items[key] = cacheItem{value, now.Add(ttl)}
+}
+
+func (c *MemoryCache) timeNow() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// Ключ кеша: endpoint - обработчик, метод и url, как у RateLimitKey, автор запроса и проверенные параметры в JSON.
+// false - ответ не кешируется: запрос не GET/HEAD или параметры не кодируются в JSON
+func CacheKey(endpoint string, r *http.Request, params ...interface{}) (string, bool) {
+	if !cacheable(r) {
+		return "", false
+	}
+	key := struct {
+		Endpoint  string
+		Principal string
+		Params    []interface{}
+	}{Endpoint: endpoint, Params: params}
+	if principal, ok := PrincipalFromContext(r.Context()); ok && principal != nil {
+		key.Principal = principal.ID
+	}
+	data, err := json.Marshal(key)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+func cacheable(r *http.Request) bool {
+	return r.Method == http.MethodGet || r.Method == http.MethodHead
+}
+
+// Пишет ответ на GET и HEAD с ETag (хеш тела в формате из Accept) и Cache-Control: max-age,
+// для private - только для браузера клиента. Если ETag есть в If-None-Match, отвечает 304 без тела.
+// Остальные запросы пишет encoder.EncodeResponse без изменений
+func WriteWithETag(w http.ResponseWriter, r *http.Request, encoder Encoder, response interface{}, maxAge time.Duration, private bool) {
+	if !cacheable(r) {
+		encoder.EncodeResponse(w, r, response)
+		return
+	}
+
+	buf := &bufferedWriter{ResponseWriter: w, status: http.StatusOK}
+	encoder.EncodeResponse(buf, r, response)
+	if buf.status != http.StatusOK {
+		w.WriteHeader(buf.status)
+		w.Write(buf.body.Bytes())
+		return
+	}
+
+	sum := sha256.Sum256(buf.body.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	cacheControl := "max-age=" + strconv.FormatInt(int64(math.Ceil(maxAge.Seconds())), 10)
+	if private {
+		cacheControl = "private, " + cacheControl
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", cacheControl)
+
+	if etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.Header().Del("Content-Length")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.body.Bytes())
+}
+
+// Слабое сравнение из RFC 7232: W/ не учитывается
+func etagMatch(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// Заголовки пишет в исходный ResponseWriter, статус и тело запоминает
+type bufferedWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
@@ -0,0 +1,68 @@
+package runtime
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWriteWithETag(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteWithETag(rec, httptest.NewRequest(http.MethodGet, "/user/profile", nil), DefaultEncoder, "ok", 30*time.Second, false)
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" || rec.Header().Get("Cache-Control") != "max-age=30" {
+		t.Fatalf("expected 200 with ETag and max-age, got %d %v", rec.Code, rec.Header())
+	}
+
+	cases := []struct {
+		Method      string
+		IfNoneMatch string
+		Status      int
+		Body        string
+	}{
+		{http.MethodGet, etag, http.StatusNotModified, ""},
+		{http.MethodGet, `"other", W/` + etag, http.StatusNotModified, ""},
+		{http.MethodGet, "*", http.StatusNotModified, ""},
+		{http.MethodGet, `"other"`, http.StatusOK, `{"error":"","response":"ok"}`},
+		// не GET и HEAD пишется без ETag
+		{http.MethodPost, etag, http.StatusOK, `{"error":"","response":"ok"}`},
+	}
+
+	for idx, item := range cases {
+		req := httptest.NewRequest(item.Method, "/user/profile", nil)
+		req.Header.Set("If-None-Match", item.IfNoneMatch)
+		rec := httptest.NewRecorder()
+		WriteWithETag(rec, req, DefaultEncoder, "ok", 30*time.Second, true)
+
+		if rec.Code != item.Status {
+			t.Errorf("[%d] expected http status %v, got %v", idx, item.Status, rec.Code)
+		}
+		if rec.Body.String() != item.Body {
+			t.Errorf("[%d] expected body %s, got %s", idx, item.Body, rec.Body.String())
+		}
+		if item.Method == http.MethodGet && rec.Header().Get("Cache-Control") != "private, max-age=30" {
+			t.Errorf("[%d] expected private Cache-Control, got %q", idx, rec.Header().Get("Cache-Control"))
+		}
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := &MemoryCache{now: func() time.Time { return now }}
+
+	cache.Set("a", 1, time.Second)
+	if value, ok := cache.Get("a"); !ok || value != 1 {
+		t.Errorf("expected cached 1, got %v %v", value, ok)
+	}
+	now = now.Add(time.Second)
+	if value, ok := cache.Get("a"); ok {
+		t.Errorf("expected expired value, got %v", value)
+	}
+
+	now = now.Add(time.Hour)
+	cache.Set("b", 2, time.Second)
+	if _, ok := cache.items["a"]; ok {
+		t.Errorf("expected expired item to be swept")
+	}
+}
@@ -0,0 +1,163 @@
+package runtime
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+// Записывает ответ метода или ошибку: статус, заголовки и тело.
+// Встроенный формат выбирается аннотацией apigen:handler {"encoder": "..."} к структуре обработчика,
+// формат, которого нет среди встроенных, обработчик пишет сам, реализовав оба метода
+type Encoder interface {
+	EncodeResponse(w http.ResponseWriter, r *http.Request, response interface{})
+	// status - статус ошибки, для ApiError - её HTTPStatus.
+	// Ошибки параметров методов с "errors": "all" приходят как ValidationErrors
+	EncodeError(w http.ResponseWriter, r *http.Request, status int, err error)
+}
+
+// Формат исходного задания {"error": ..., "response": ...} для обработчиков без аннотации
+var DefaultEncoder Encoder = EnvelopeEncoder{}
+
+// Встроенные Encoder выбирают формат по заголовку Accept из Formats, пустой - DefaultFormats.
+// Ошибку, когда ни один формат не подходит, пишут в первом формате
+
+// {"error": "", "response": ...} и {"error": "...", "errors": [...]}
+type EnvelopeEncoder struct {
+	Formats []*Format
+	// ID запроса в теле каждой ошибки, а не только присланный клиентом
+	RequestID bool
+}
+
+func (e EnvelopeEncoder) Acceptable(r *http.Request) bool {
+	return acceptable(r, e.Formats)
+}
+
+func (e EnvelopeEncoder) EncodeResponse(w http.ResponseWriter, r *http.Request, response interface{}) {
+	format, contentType := negotiate(r, e.Formats, false)
+	writeFormat(w, format, contentType, http.StatusOK, Result{Response: response}, resultRoot)
+}
+
+func (e EnvelopeEncoder) EncodeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	format, contentType := negotiate(r, e.Formats, false)
+	writeFormat(w, format, contentType, status, errorResult(r, err, e.RequestID), resultRoot)
+}
+
+// Ответ метода без обёртки, ошибки - как у EnvelopeEncoder, но без "response"
+type JSONEncoder struct {
+	Formats   []*Format
+	RequestID bool
+}
+
+func (e JSONEncoder) Acceptable(r *http.Request) bool {
+	return acceptable(r, e.Formats)
+}
+
+func (e JSONEncoder) EncodeResponse(w http.ResponseWriter, r *http.Request, response interface{}) {
+	format, contentType := negotiate(r, e.Formats, false)
+	writeFormat(w, format, contentType, http.StatusOK, response, responseRoot)
+}
+
+func (e JSONEncoder) EncodeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	format, contentType := negotiate(r, e.Formats, false)
+	writeFormat(w, format, contentType, status, errorResult(r, err, e.RequestID), resultRoot)
+}
+
+// Ответ метода без обёртки, ошибки - application/problem+json (и problem+xml) по RFC 7807
+type ProblemEncoder struct {
+	Formats   []*Format
+	RequestID bool
+}
+
+// Описание ошибки по RFC 7807. Errors - расширение с ошибками параметров, RequestID - с ID запроса
+type Problem struct {
+	Type      string       `json:"type" xml:"type"`
+	Title     string       `json:"title" xml:"title"`
+	Status    int          `json:"status" xml:"status"`
+	Detail    string       `json:"detail,omitempty" xml:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty" xml:"instance,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty" xml:"errors,omitempty"`
+	RequestID string       `json:"request_id,omitempty" xml:"request_id,omitempty"`
+}
+
+func (e ProblemEncoder) Acceptable(r *http.Request) bool {
+	return acceptable(r, e.Formats)
+}
+
+func (e ProblemEncoder) EncodeResponse(w http.ResponseWriter, r *http.Request, response interface{}) {
+	format, contentType := negotiate(r, e.Formats, false)
+	writeFormat(w, format, contentType, http.StatusOK, response, responseRoot)
+}
+
+func (e ProblemEncoder) EncodeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	format, _ := negotiate(r, e.Formats, true)
+	res := errorResult(r, err, e.RequestID)
+	// для about:blank title - стандартное название статуса
+	writeFormat(w, format, format.ProblemMediaType, status, Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    res.Error,
+		Instance:  r.URL.Path,
+		Errors:    res.Errors,
+		RequestID: res.RequestID,
+	}, problemRoot)
+}
+
+// ID запроса из контекста (его кладёт PropagateRequestID) попадает в тело ошибки, если кодировщику
+// задан withRequestID. Без него в теле только ID, который прислал клиент: так тела ошибок
+// на запросы без X-Request-ID и traceparent остаются такими же, как до появления ID
+func errorResult(r *http.Request, err error, withRequestID bool) Result {
+	res := Result{Error: err.Error()}
+	var ok bool
+	if withRequestID {
+		res.RequestID, ok = RequestIDFromContext(r.Context())
+	}
+	if !ok {
+		res.RequestID, _ = incomingRequestID(r)
+	}
+	if fieldErrors, ok := err.(ValidationErrors); ok {
+		res.Errors = fieldErrors
+	}
+	return res
+}
+
+func formatsOrDefault(formats []*Format) []*Format {
+	if len(formats) == 0 {
+		return DefaultFormats
+	}
+	return formats
+}
+
+// Подходит ли формат для ответа метода: типы ошибок ProblemEncoder для этого не подходят
+func acceptable(r *http.Request, formats []*Format) bool {
+	format, _ := NegotiateFormat(r, formatsOrDefault(formats))
+	return format != nil
+}
+
+func negotiate(r *http.Request, formats []*Format, withProblem bool) (*Format, string) {
+	formats = formatsOrDefault(formats)
+	if format, mediaType := negotiateFormat(r, formats, withProblem); format != nil {
+		return format, mediaType
+	}
+	return formats[0], formats[0].MediaTypes[0]
+}
+
+// Корневые элементы XML, у problem - пространство имён из RFC 7807
+var (
+	resultRoot   = xml.Name{Local: "result"}
+	responseRoot = xml.Name{Local: "response"}
+	problemRoot  = xml.Name{Space: "urn:ietf:rfc:7807", Local: "problem"}
+)
+
+func writeFormat(w http.ResponseWriter, format *Format, contentType string, status int, value interface{}, root xml.Name) {
+	body, err := format.Marshal(value, root)
+	if err != nil {
+		status = http.StatusInternalServerError
+		body, _ = format.Marshal(Result{Error: "internal error"}, resultRoot)
+		contentType = format.MediaTypes[0]
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(status)
+	WriteResponse(w, body)
+}
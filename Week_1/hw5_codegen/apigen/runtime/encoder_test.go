@@ -0,0 +1,128 @@
+package runtime
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type EncoderCase struct {
+	Encoder     Encoder
+	Accept      string
+	Status      int
+	Err         error
+	Response    interface{}
+	ContentType string
+	Body        string
+}
+
+func TestEncoders(t *testing.T) {
+	fieldErrors := ValidationErrors{{Param: "age", Rule: "type", Message: "age must be int"}}
+
+	type User struct {
+		ID    int    `json:"id" xml:"id"`
+		Login string `json:"login" xml:"login"`
+	}
+
+	cases := []EncoderCase{
+		{EnvelopeEncoder{}, "", http.StatusOK, nil, map[string]int{"id": 1}, "application/json",
+			`{"error":"","response":{"id":1}}`},
+		{EnvelopeEncoder{}, "", http.StatusNotFound, errors.New("user not exist"), nil, "application/json",
+			`{"error":"user not exist"}`},
+		{EnvelopeEncoder{}, "", http.StatusBadRequest, fieldErrors, nil, "application/json",
+			`{"error":"age must be int","errors":[{"param":"age","rule":"type","message":"age must be int"}]}`},
+		{JSONEncoder{}, "", http.StatusOK, nil, map[string]int{"id": 1}, "application/json",
+			`{"id":1}`},
+		{JSONEncoder{}, "", http.StatusNotFound, errors.New("user not exist"), nil, "application/json",
+			`{"error":"user not exist"}`},
+		{ProblemEncoder{}, "", http.StatusOK, nil, []int{1, 2}, "application/json",
+			`[1,2]`},
+		{ProblemEncoder{}, "", http.StatusNotFound, errors.New("user not exist"), nil, "application/problem+json",
+			`{"type":"about:blank","title":"Not Found","status":404,"detail":"user not exist","instance":"/user/profile"}`},
+		{ProblemEncoder{}, "", http.StatusBadRequest, fieldErrors, nil, "application/problem+json",
+			`{"type":"about:blank","title":"Bad Request","status":400,"detail":"age must be int","instance":"/user/profile",` +
+				`"errors":[{"param":"age","rule":"type","message":"age must be int"}]}`},
+		{EnvelopeEncoder{}, "application/xml", http.StatusOK, nil, User{42, "rvasily"}, "application/xml",
+			xml.Header + `<result><error></error><response><id>42</id><login>rvasily</login></response></result>`},
+		{EnvelopeEncoder{}, "text/html;q=0.9, text/*;q=0.5, */*;q=0.1", http.StatusBadRequest, fieldErrors, nil, "text/xml",
+			xml.Header + `<result><error>age must be int</error><errors param="age" rule="type">age must be int</errors></result>`},
+		{JSONEncoder{}, "application/xml", http.StatusOK, nil, []int{1, 2}, "application/xml",
+			xml.Header + `<response>1</response><response>2</response>`},
+		{ProblemEncoder{}, "application/problem+xml", http.StatusNotFound, errors.New("user not exist"), nil, "application/problem+xml",
+			xml.Header + `<problem xmlns="urn:ietf:rfc:7807"><type>about:blank</type><title>Not Found</title><status>404</status>` +
+				`<detail>user not exist</detail><instance>/user/profile</instance></problem>`},
+		{EnvelopeEncoder{}, "application/msgpack", http.StatusOK, nil, User{42, "rvasily"}, "application/msgpack",
+			"\x82\xa5error\xa0\xa8response\x82\xa2id*\xa5login\xa7rvasily"},
+		{EnvelopeEncoder{}, "application/xml;q=0.5, application/json;q=0.4", http.StatusOK, nil, []int{1}, "application/xml",
+			xml.Header + `<result><error></error><response>1</response></result>`},
+		{EnvelopeEncoder{}, "application/*;q=0.1, application/msgpack;q=0", http.StatusOK, nil, []int{1}, "application/json",
+			`{"error":"","response":[1]}`},
+		// ни один формат не подходит: ошибка в первом формате
+		{EnvelopeEncoder{}, "text/html", http.StatusNotAcceptable, errors.New("not acceptable"), nil, "application/json",
+			`{"error":"not acceptable"}`},
+		// успешный ответ не подписывается типом RFC 7807
+		{ProblemEncoder{}, "application/problem+json", http.StatusOK, nil, []int{1, 2}, "application/json",
+			`[1,2]`},
+		{ProblemEncoder{}, "application/problem+json", http.StatusNotAcceptable, errors.New("not acceptable"), nil, "application/problem+json",
+			`{"type":"about:blank","title":"Not Acceptable","status":406,"detail":"not acceptable","instance":"/user/profile"}`},
+		{EnvelopeEncoder{}, "application/problem+json", http.StatusNotAcceptable, errors.New("not acceptable"), nil, "application/json",
+			`{"error":"not acceptable"}`},
+		{EnvelopeEncoder{}, "application/json", http.StatusInternalServerError, nil, map[string]interface{}{"f": func() {}}, "application/json",
+			`{"error":"internal error"}`},
+	}
+
+	for idx, item := range cases {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/user/profile?login=rvasily", nil)
+		if item.Accept != "" {
+			r.Header.Set("Accept", item.Accept)
+		}
+		if item.Err != nil {
+			item.Encoder.EncodeError(w, r, item.Status, item.Err)
+		} else {
+			item.Encoder.EncodeResponse(w, r, item.Response)
+		}
+
+		if w.Code != item.Status {
+			t.Errorf("[%d] expected http status %v, got %v", idx, item.Status, w.Code)
+		}
+		if contentType := w.Header().Get("Content-Type"); contentType != item.ContentType {
+			t.Errorf("[%d] expected content type %q, got %q", idx, item.ContentType, contentType)
+		}
+		if body := w.Body.String(); body != item.Body {
+			t.Errorf("[%d] results not match\nGot: %s\nExpected: %s", idx, body, item.Body)
+		}
+	}
+}
+
+func TestAcceptable(t *testing.T) {
+	cases := map[string]bool{
+		"":                                 true,
+		"*/*":                              true,
+		"application/json":                 true,
+		"text/xml":                         true,
+		"application/x-msgpack;q=0.3":      true,
+		"text/html":                        false,
+		"application/json;q=0, text/plain": false,
+		"application/*;q=0":                false,
+		// типы RFC 7807 - только для ошибок ProblemEncoder, ответ метода в них не пишется
+		"application/problem+json": false,
+		"application/problem+xml":  false,
+	}
+
+	for accept, expected := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/user/profile", nil)
+		r.Header.Set("Accept", accept)
+		if res := Acceptable(EnvelopeEncoder{}, r); res != expected {
+			t.Errorf("[%s] expected %v, got %v", accept, expected, res)
+		}
+		if res := Acceptable(ProblemEncoder{}, r); res != expected {
+			t.Errorf("[%s] expected %v for problem encoder, got %v", accept, expected, res)
+		}
+		if res := Acceptable(EnvelopeEncoder{Formats: []*Format{MsgPackFormat}}, r); accept == "text/xml" && res {
+			t.Errorf("[%s] msgpack only encoder accepts xml", accept)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package runtime
+
+import "strings"
+
+// Ошибка проверки одного параметра: имя параметра, правило и текст ошибки
+type FieldError struct {
+	Param   string `json:"param" xml:"param,attr"`
+	Rule    string `json:"rule" xml:"rule,attr"`
+	Message string `json:"message" xml:",chardata"`
+}
+
+func (e FieldError) Error() string {
+	return e.Message
+}
+
+// Ошибки всех параметров для методов с "errors": "all"
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Есть ли уже ошибка параметра: правила сравнения полей для него не проверяются
+func (e ValidationErrors) Has(param string) bool {
+	for _, fieldErr := range e {
+		if fieldErr.Param == param {
+			return true
+		}
+	}
+	return false
+}
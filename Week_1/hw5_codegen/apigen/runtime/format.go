@@ -0,0 +1,155 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Формат тела ответа встроенных Encoder
+type Format struct {
+	// Типы, которые подходят для Accept, первый - Content-Type ответа
+	MediaTypes []string
+	// Content-Type ошибок ProblemEncoder. Для Accept подходит, только когда ProblemEncoder пишет ошибку
+	ProblemMediaType string
+	// root - имя корневого элемента для форматов, которым оно нужно
+	Marshal func(v interface{}, root xml.Name) ([]byte, error)
+}
+
+var (
+	JSONFormat = &Format{
+		MediaTypes:       []string{"application/json"},
+		ProblemMediaType: "application/problem+json",
+		Marshal: func(v interface{}, root xml.Name) ([]byte, error) {
+			return json.Marshal(v)
+		},
+	}
+	// map и interface{} без конкретного значения encoding/xml не кодирует, на таких ответах будет 500
+	XMLFormat = &Format{
+		MediaTypes:       []string{"application/xml", "text/xml"},
+		ProblemMediaType: "application/problem+xml",
+		Marshal:          marshalXml,
+	}
+	// Те же поля, что в JSON: объекты - map с ключами из json-тегов, числа - int или float64
+	MsgPackFormat = &Format{
+		MediaTypes:       []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"},
+		ProblemMediaType: "application/msgpack",
+		Marshal:          marshalMsgPack,
+	}
+)
+
+// Форматы встроенных Encoder, у которых не заданы свои. При одинаковом q в Accept выбирается первый
+var DefaultFormats = []*Format{JSONFormat, XMLFormat, MsgPackFormat}
+
+// Encoder, который выбирает формат по заголовку Accept и может заранее сказать, что ни один не подходит
+type Negotiator interface {
+	Acceptable(r *http.Request) bool
+}
+
+// Сможет ли encoder ответить на запрос r. Encoder, который не реализует Negotiator, отвечает всегда
+func Acceptable(encoder Encoder, r *http.Request) bool {
+	if negotiator, ok := encoder.(Negotiator); ok {
+		return negotiator.Acceptable(r)
+	}
+	return true
+}
+
+// Формат с наибольшим q из Accept и подходящий тип из его MediaTypes, nil - ни один формат не подходит.
+// Без Accept - первый формат
+func NegotiateFormat(r *http.Request, formats []*Format) (*Format, string) {
+	return negotiateFormat(r, formats, false)
+}
+
+// withProblem - к MediaTypes добавляется ProblemMediaType, для ошибок ProblemEncoder
+func negotiateFormat(r *http.Request, formats []*Format, withProblem bool) (*Format, string) {
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return formats[0], formats[0].MediaTypes[0]
+	}
+	ranges := parseAccept(accept)
+
+	var best *Format
+	bestQ, bestType := 0.0, ""
+	for _, format := range formats {
+		if q, mediaType := format.quality(ranges, withProblem); q > bestQ {
+			best, bestQ, bestType = format, q, mediaType
+		}
+	}
+	return best, bestType
+}
+
+// Элемент Accept: text/*;q=0.5
+type mediaRange struct {
+	Type    string
+	Subtype string
+	Q       float64
+}
+
+func parseAccept(accept string) []mediaRange {
+	res := make([]mediaRange, 0)
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		slash := strings.Index(mediaType, "/")
+		if slash == -1 {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		res = append(res, mediaRange{mediaType[:slash], mediaType[slash+1:], q})
+	}
+	return res
+}
+
+// Тип формата с наибольшим q. Для каждого типа берётся q самого точного элемента Accept:
+// type/subtype важнее type/*, type/* важнее */*. При равных q - первый тип
+func (f *Format) quality(ranges []mediaRange, withProblem bool) (float64, string) {
+	mediaTypes := f.MediaTypes
+	if withProblem {
+		mediaTypes = append(mediaTypes[:len(mediaTypes):len(mediaTypes)], f.ProblemMediaType)
+	}
+	best, bestType := 0.0, ""
+	for _, mediaType := range mediaTypes {
+		slash := strings.Index(mediaType, "/")
+		mainType, subtype := mediaType[:slash], mediaType[slash+1:]
+
+		precision, q := -1, 0.0
+		for _, item := range ranges {
+			rangePrecision := -1
+			switch {
+			case item.Type == mainType && item.Subtype == subtype:
+				rangePrecision = 2
+			case item.Type == mainType && item.Subtype == "*":
+				rangePrecision = 1
+			case item.Type == "*" && item.Subtype == "*":
+				rangePrecision = 0
+			}
+			if rangePrecision > precision {
+				precision, q = rangePrecision, item.Q
+			}
+		}
+		if q > best {
+			best, bestType = q, mediaType
+		}
+	}
+	return best, bestType
+}
+
+func marshalXml(v interface{}, root xml.Name) ([]byte, error) {
+	var out bytes.Buffer
+	out.WriteString(xml.Header)
+	if err := xml.NewEncoder(&out).EncodeElement(v, xml.StartElement{Name: root}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,44 @@
+package runtime
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Проверки format=email|uuid|url|ipv4
+
+func ValidateFormatEmail(value, valueName string) error {
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value {
+		return FieldError{valueName, "format", valueName + " must be a valid email"}
+	}
+	return nil
+}
+
+var formatUUIDRe = regexp.MustCompile("^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$")
+
+func ValidateFormatUUID(value, valueName string) error {
+	if !formatUUIDRe.MatchString(value) {
+		return FieldError{valueName, "format", valueName + " must be a valid uuid"}
+	}
+	return nil
+}
+
+func ValidateFormatURL(value, valueName string) error {
+	u, err := url.ParseRequestURI(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return FieldError{valueName, "format", valueName + " must be a valid url"}
+	}
+	return nil
+}
+
+func ValidateFormatIPv4(value, valueName string) error {
+	ip := net.ParseIP(value)
+	if ip == nil || ip.To4() == nil || strings.Contains(value, ":") {
+		return FieldError{valueName, "format", valueName + " must be a valid ipv4"}
+	}
+	return nil
+}
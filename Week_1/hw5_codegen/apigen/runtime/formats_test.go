@@ -0,0 +1,39 @@
+package runtime
+
+import "testing"
+
+func TestValidateFormats(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Validate func(value, valueName string) error
+		Valid    []string
+		Invalid  []string
+	}{
+		{"email", ValidateFormatEmail,
+			[]string{"ann@example.com", "a.b+c@mail.example.org"},
+			[]string{"", "ann", "ann@", "Ann <ann@example.com>", " ann@example.com"}},
+		{"uuid", ValidateFormatUUID,
+			[]string{"123e4567-e89b-12d3-a456-426614174000", "123E4567-E89B-12D3-A456-426614174000"},
+			[]string{"", "123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400g", "{123e4567-e89b-12d3-a456-426614174000}"}},
+		{"url", ValidateFormatURL,
+			[]string{"https://example.com", "http://localhost:8080/path?q=1"},
+			[]string{"", "example.com", "/relative/path", "https://", "mailto:ann@example.com"}},
+		{"ipv4", ValidateFormatIPv4,
+			[]string{"127.0.0.1", "192.168.0.255"},
+			[]string{"", "256.0.0.1", "1.2.3", "::1", "::ffff:127.0.0.1"}},
+	}
+
+	for _, item := range cases {
+		for _, value := range item.Valid {
+			if err := item.Validate(value, "value"); err != nil {
+				t.Errorf("%s: unexpected error for %q: %v", item.Name, value, err)
+			}
+		}
+		for _, value := range item.Invalid {
+			expected := FieldError{"value", "format", "value must be a valid " + item.Name}
+			if err := item.Validate(value, "value"); err != expected {
+				t.Errorf("%s: expected error for %q, got %#v", item.Name, value, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+)
+
+// Ошибка, которую клиент получил как 5xx: паника или ошибка метода, которая не ApiError
+type ErrorEvent struct {
+	// Структура и метод обработчика: "MyApi.Create"
+	Handler string
+	// Статус, с которым ответили клиенту
+	Status int
+	Err    error
+	// Значение recover() и стек паники, nil для ошибок метода
+	Panic interface{}
+	Stack []byte
+}
+
+// Получает ошибки обработчика вместе с запросом, на котором они случились.
+// Чтобы отправлять их в свою систему логов или трекер ошибок, обработчик реализует LogError
+type Logger interface {
+	LogError(r *http.Request, event ErrorEvent)
+}
+
+// Пишет в стандартный log, то есть в stderr процесса
+var DefaultLogger Logger = StdLogger{}
+
+// Пишет ошибки одной строкой key=value (стек паники - следом) в Logger, nil - стандартный log
+type StdLogger struct {
+	Logger *log.Logger
+}
+
+func (l StdLogger) LogError(r *http.Request, event ErrorEvent) {
+	requestID, _ := RequestIDFromContext(r.Context())
+	msg := fmt.Sprintf("apigen: handler=%s request_id=%s method=%s path=%q remote=%s status=%d error=%q",
+		event.Handler, requestID, r.Method, r.URL.Path, r.RemoteAddr, event.Status, event.Err.Error())
+	if event.Panic != nil {
+		msg += "\n" + string(event.Stack)
+	}
+	if l.Logger != nil {
+		l.Logger.Print(msg)
+	} else {
+		log.Print(msg)
+	}
+}
+
+// Для defer в обёртке: p - значение recover(). Передаёт панику в logger и отвечает 500 "internal error".
+// http.ErrAbortHandler пробрасывается дальше, чтобы net/http оборвал ответ без записи в лог
+func RecoverPanic(w http.ResponseWriter, r *http.Request, encoder Encoder, logger Logger, handler string, p interface{}) {
+	if p == http.ErrAbortHandler {
+		panic(p)
+	}
+	logger.LogError(r, ErrorEvent{
+		Handler: handler,
+		Status:  http.StatusInternalServerError,
+		Err:     fmt.Errorf("panic: %v", p),
+		Panic:   p,
+		Stack:   debug.Stack(),
+	})
+	encoder.EncodeError(w, r, http.StatusInternalServerError, errors.New("internal error"))
+}
@@ -0,0 +1,166 @@
+package runtime
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Получает от обёрток данные о запросах. url - url метода из apigen:api, для методов с параметрами
+// пути - шаблон: "/user/{id}". Реализовав оба метода, обработчик передаёт их в свою систему метрик
+type Metrics interface {
+	// Запрос обработан: статус ответа и время от начала обёртки до конца ответа
+	ObserveRequest(url string, status int, duration time.Duration)
+	// Запрос не прошёл разбор или проверку параметров, ответ 400
+	ObserveValidationFailure(url string)
+}
+
+// Метрики не собираются, пока DefaultMetrics не заменят, например, на &MemoryMetrics{}
+var DefaultMetrics Metrics = NopMetrics{}
+
+// Ничего не делает
+type NopMetrics struct{}
+
+func (NopMetrics) ObserveRequest(url string, status int, duration time.Duration) {}
+
+func (NopMetrics) ObserveValidationFailure(url string) {}
+
+// Считает метрики в памяти и отдаёт их как http.Handler в текстовом формате Prometheus:
+// apigen_requests_total{url,status}, apigen_request_duration_seconds{url} (summary без квантилей)
+// и apigen_validation_failures_total{url}. Счётчики создаются при первом запросе, конструктор не нужен
+type MemoryMetrics struct {
+	mu          sync.Mutex
+	requests    map[requestKey]uint64
+	durations   map[string]*durationSum
+	validations map[string]uint64
+}
+
+type requestKey struct {
+	url    string
+	status int
+}
+
+type durationSum struct {
+	seconds float64
+	count   uint64
+}
+
+func (m *MemoryMetrics) ObserveRequest(url string, status int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.requests == nil {
+		m.requests = map[requestKey]uint64{}
+		m.durations = map[string]*durationSum{}
+	}
+	m.requests[requestKey{url, status}]++
+	sum, ok := m.durations[url]
+	if !ok {
+		sum = &durationSum{}
+		m.durations[url] = sum
+	}
+	sum.seconds += duration.Seconds()
+	sum.count++
+}
+
+func (m *MemoryMetrics) ObserveValidationFailure(url string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.validations == nil {
+		m.validations = map[string]uint64{}
+	}
+	m.validations[url]++
+}
+
+func (m *MemoryMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write([]byte(m.prometheusText()))
+}
+
+func (m *MemoryMetrics) prometheusText() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sb strings.Builder
+
+	requests := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		requests = append(requests, key)
+	}
+	sort.Slice(requests, func(a, b int) bool {
+		if requests[a].url != requests[b].url {
+			return requests[a].url < requests[b].url
+		}
+		return requests[a].status < requests[b].status
+	})
+	sb.WriteString("# HELP apigen_requests_total Requests handled by generated wrappers.\n")
+	sb.WriteString("# TYPE apigen_requests_total counter\n")
+	for _, key := range requests {
+		fmt.Fprintf(&sb, "apigen_requests_total{url=%s,status=\"%d\"} %d\n", promLabel(key.url), key.status, m.requests[key])
+	}
+
+	sb.WriteString("# HELP apigen_request_duration_seconds Time spent in generated wrappers.\n")
+	sb.WriteString("# TYPE apigen_request_duration_seconds summary\n")
+	urls := make([]string, 0, len(m.durations))
+	for url := range m.durations {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	for _, url := range urls {
+		sum := m.durations[url]
+		fmt.Fprintf(&sb, "apigen_request_duration_seconds_sum{url=%s} %g\n", promLabel(url), sum.seconds)
+		fmt.Fprintf(&sb, "apigen_request_duration_seconds_count{url=%s} %d\n", promLabel(url), sum.count)
+	}
+
+	sb.WriteString("# HELP apigen_validation_failures_total Requests rejected with 400 on params.\n")
+	sb.WriteString("# TYPE apigen_validation_failures_total counter\n")
+	urls = urls[:0]
+	for url := range m.validations {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	for _, url := range urls {
+		fmt.Fprintf(&sb, "apigen_validation_failures_total{url=%s} %d\n", promLabel(url), m.validations[url])
+	}
+
+	return sb.String()
+}
+
+// Значение метки в кавычках, с экранированием \, " и перевода строки
+func promLabel(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
+
+// Запоминает статус ответа для Metrics. Без WriteHeader и Write статус - 200
+type StatusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *StatusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *StatusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *StatusWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Исходный ResponseWriter для http.ResponseController
+func (w *StatusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,134 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Кодирует значение в MessagePack через JSON, чтобы поля и их имена совпадали с JSON-ответом
+func marshalMsgPack(v interface{}, root xml.Name) ([]byte, error) {
+	jsonBody, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(jsonBody))
+	decoder.UseNumber()
+	if err = decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err = writeMsgPack(&out, value); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func writeMsgPack(out *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		out.WriteByte(0xc0)
+	case bool:
+		if v {
+			out.WriteByte(0xc3)
+		} else {
+			out.WriteByte(0xc2)
+		}
+	case json.Number:
+		return writeMsgPackNumber(out, v)
+	case string:
+		writeMsgPackHeader(out, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		out.WriteString(v)
+	case []interface{}:
+		writeMsgPackHeader(out, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range v {
+			if err := writeMsgPack(out, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		// ключи по порядку, чтобы одинаковые ответы кодировались одинаково
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		writeMsgPackHeader(out, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		for _, key := range keys {
+			if err := writeMsgPack(out, key); err != nil {
+				return err
+			}
+			if err := writeMsgPack(out, v[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported value %T", value)
+	}
+	return nil
+}
+
+// Длина строки, массива или map: fix-формат для коротких, иначе 8 (только строки), 16 или 32 бита
+func writeMsgPackHeader(out *bytes.Buffer, n int, fix byte, fixLimit int, code8, code16, code32 byte) {
+	switch {
+	case n < fixLimit:
+		out.WriteByte(fix | byte(n))
+	case code8 != 0 && n <= math.MaxUint8:
+		out.WriteByte(code8)
+		out.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		out.WriteByte(code16)
+		_ = binary.Write(out, binary.BigEndian, uint16(n))
+	default:
+		out.WriteByte(code32)
+		_ = binary.Write(out, binary.BigEndian, uint32(n))
+	}
+}
+
+func writeMsgPackNumber(out *bytes.Buffer, number json.Number) error {
+	if i, err := number.Int64(); err == nil {
+		switch {
+		case i >= 0 && i <= math.MaxInt8:
+			out.WriteByte(byte(i))
+		case i < 0 && i >= -32:
+			out.WriteByte(byte(int8(i)))
+		case i >= math.MinInt8 && i <= math.MaxInt8:
+			out.WriteByte(0xd0)
+			out.WriteByte(byte(int8(i)))
+		case i >= math.MinInt16 && i <= math.MaxInt16:
+			out.WriteByte(0xd1)
+			_ = binary.Write(out, binary.BigEndian, int16(i))
+		case i >= math.MinInt32 && i <= math.MaxInt32:
+			out.WriteByte(0xd2)
+			_ = binary.Write(out, binary.BigEndian, int32(i))
+		default:
+			out.WriteByte(0xd3)
+			_ = binary.Write(out, binary.BigEndian, i)
+		}
+		return nil
+	}
+
+	// больше math.MaxInt64
+	if u, err := strconv.ParseUint(number.String(), 10, 64); err == nil {
+		out.WriteByte(0xcf)
+		_ = binary.Write(out, binary.BigEndian, u)
+		return nil
+	}
+
+	f, err := number.Float64()
+	if err != nil {
+		return err
+	}
+	out.WriteByte(0xcb)
+	_ = binary.Write(out, binary.BigEndian, f)
+	return nil
+}
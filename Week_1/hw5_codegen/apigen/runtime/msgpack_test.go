@@ -0,0 +1,49 @@
+package runtime
+
+import (
+	"encoding/hex"
+	"encoding/xml"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestMarshalMsgPack(t *testing.T) {
+	cases := []struct {
+		Value    interface{}
+		Expected string
+	}{
+		{nil, "c0"},
+		{true, "c3"},
+		{false, "c2"},
+		{0, "00"},
+		{127, "7f"},
+		{-1, "ff"},
+		{-32, "e0"},
+		{-33, "d0df"},
+		{200, "d100c8"},
+		{-40000, "d2ffff63c0"},
+		{int64(math.MaxInt64), "d37fffffffffffffff"},
+		{uint64(math.MaxUint64), "cfffffffffffffffff"},
+		{1.5, "cb3ff8000000000000"},
+		{"", "a0"},
+		{"abc", "a3616263"},
+		{strings.Repeat("a", 32), "d920" + strings.Repeat("61", 32)},
+		{strings.Repeat("a", 256), "da0100" + strings.Repeat("61", 256)},
+		{[]int{1, 2}, "920102"},
+		{make([]int, 16), "dc0010" + strings.Repeat("00", 16)},
+		{map[string]int{"b": 2, "a": 1}, "82a16101a16202"},
+		{FieldError{Param: "age", Rule: "min", Message: "m"}, "83a76d657373616765a16da5706172616da3616765a472756c65a36d696e"},
+	}
+
+	for idx, item := range cases {
+		res, err := marshalMsgPack(item.Value, xml.Name{})
+		if err != nil {
+			t.Errorf("[%d] unexpected error: %v", idx, err)
+			continue
+		}
+		if got := hex.EncodeToString(res); got != item.Expected {
+			t.Errorf("[%d] results not match\nGot: %s\nExpected: %s", idx, got, item.Expected)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Наибольший размер тела с JSON
+var MaxJSONBodySize int64 = 10 << 20
+
+// Наибольший размер тела формы, multipart - вместе с файлами. Больше 10MB application/x-www-form-urlencoded
+// не прочитает сам ParseForm, такое тело будет ошибкой 400
+var MaxFormBodySize int64 = 10 << 20
+
+// Тело больше MaxJSONBodySize или MaxFormBodySize, обёртка отвечает на него 413
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// Параметры запроса из query и тела: формы или JSON-объекта
+func ReadParams(r *http.Request) (url.Values, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		// ParseForm и ParseMultipartForm возвращают превышение своих ограничений ошибками без типа,
+		// поэтому размер тела считает limitedBody
+		var body *limitedBody
+		if r.Body != nil {
+			body = &limitedBody{ReadCloser: r.Body, left: MaxFormBodySize}
+			r.Body = body
+		}
+		err := r.ParseMultipartForm(32 << 20)
+		if body != nil && body.exceeded {
+			return nil, ErrBodyTooLarge
+		}
+		if err != nil && err != http.ErrNotMultipart {
+			return nil, err
+		}
+		return r.Form, nil
+	}
+
+	// MaxBytesReader отдаёт ровно MaxJSONBodySize байт и ошибку, если тело длиннее
+	data, err := ioutil.ReadAll(http.MaxBytesReader(nil, r.Body, MaxJSONBodySize))
+	if err != nil {
+		if int64(len(data)) == MaxJSONBodySize {
+			return nil, ErrBodyTooLarge
+		}
+		return nil, fmt.Errorf("invalid json body")
+	}
+
+	var body interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&body); err != nil {
+		return nil, fmt.Errorf("invalid json body")
+	}
+
+	bodyMap, ok := body.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid json body")
+	}
+
+	// значения из тела важнее значений из query, как у r.FormValue
+	params := make(url.Values)
+	if err := flattenJson(params, "", bodyMap); err != nil {
+		return nil, err
+	}
+	for key, values := range r.URL.Query() {
+		params[key] = append(params[key], values...)
+	}
+
+	return params, nil
+}
+
+// Отдаёт не больше left байт, на следующем байте - ErrBodyTooLarge
+type limitedBody struct {
+	io.ReadCloser
+	left     int64
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, ErrBodyTooLarge
+	}
+	// байт сверх left читается, чтобы отличить тело ровно в left байт от более длинного
+	if int64(len(p)) > b.left+1 {
+		p = p[:b.left+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.left {
+		n, b.left, b.exceeded = int(b.left), 0, true
+		return n, ErrBodyTooLarge
+	}
+	b.left -= int64(n)
+	return n, err
+}
+
+// Параметры из пути важнее параметров из query и тела
+func MergeParams(params, pathParams url.Values) url.Values {
+	if len(pathParams) == 0 {
+		return params
+	}
+	merged := make(url.Values, len(params)+len(pathParams))
+	for key, values := range params {
+		merged[key] = values
+	}
+	for key, values := range pathParams {
+		merged[key] = values
+	}
+	return merged
+}
+
+func flattenJson(params url.Values, prefix string, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+	case string:
+		params.Add(prefix, v)
+	case json.Number:
+		params.Add(prefix, v.String())
+	case bool:
+		params.Add(prefix, strconv.FormatBool(v))
+	case []interface{}:
+		for _, item := range v {
+			if _, isMap := item.(map[string]interface{}); isMap {
+				return fmt.Errorf("%s must not contain objects", prefix)
+			}
+			if err := flattenJson(params, prefix, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			if err := flattenJson(params, key, item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
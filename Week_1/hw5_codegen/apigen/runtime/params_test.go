@@ -0,0 +1,86 @@
+package runtime
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReadParamsJSONBodyLimit(t *testing.T) {
+	defaultSize := MaxJSONBodySize
+	MaxJSONBodySize = 16
+	defer func() { MaxJSONBodySize = defaultSize }()
+
+	cases := []struct {
+		Body string
+		Err  error
+	}{
+		{`{"login":"ab"}`, nil},
+		// ровно MaxJSONBodySize байт
+		{`{"login":"abcd"}`, nil},
+		{`{"login":"abcde"}`, ErrBodyTooLarge},
+		{`{"login":"` + strings.Repeat("a", 1<<20) + `"}`, ErrBodyTooLarge},
+	}
+
+	for idx, item := range cases {
+		req := httptest.NewRequest("POST", "/user/create", strings.NewReader(item.Body))
+		req.Header.Set("Content-Type", "application/json")
+		params, err := ReadParams(req)
+		if err != item.Err {
+			t.Errorf("[%d] expected error %v, got %v", idx, item.Err, err)
+		}
+		if err == nil && params.Get("login") == "" {
+			t.Errorf("[%d] expected login param, got %v", idx, params)
+		}
+	}
+}
+
+func TestReadParamsFormBodyLimit(t *testing.T) {
+	defaultSize := MaxFormBodySize
+	MaxFormBodySize = 16
+	defer func() { MaxFormBodySize = defaultSize }()
+
+	multipartBody := func(login string) (string, string) {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		w.WriteField("login", login)
+		w.Close()
+		return buf.String(), w.FormDataContentType()
+	}
+	small, smallType := multipartBody("ab")
+	large, largeType := multipartBody(strings.Repeat("a", 1<<20))
+
+	cases := []struct {
+		Body        string
+		ContentType string
+		Err         error
+	}{
+		{"login=ab", "application/x-www-form-urlencoded", nil},
+		// ровно MaxFormBodySize байт
+		{"login=abcdefghij", "application/x-www-form-urlencoded", nil},
+		{"login=abcdefghijk", "application/x-www-form-urlencoded", ErrBodyTooLarge},
+		{"login=" + strings.Repeat("a", 1<<20), "application/x-www-form-urlencoded", ErrBodyTooLarge},
+		{large, largeType, ErrBodyTooLarge},
+	}
+	for idx, item := range cases {
+		req := httptest.NewRequest("POST", "/user/create", strings.NewReader(item.Body))
+		req.Header.Set("Content-Type", item.ContentType)
+		params, err := ReadParams(req)
+		if err != item.Err {
+			t.Errorf("[%d] expected error %v, got %v", idx, item.Err, err)
+		}
+		if err == nil && params.Get("login") == "" {
+			t.Errorf("[%d] expected login param, got %v", idx, params)
+		}
+	}
+
+	// multipart в пределах ограничения
+	MaxFormBodySize = int64(len(small))
+	req := httptest.NewRequest("POST", "/user/create", strings.NewReader(small))
+	req.Header.Set("Content-Type", smallType)
+	if params, err := ReadParams(req); err != nil || params.Get("login") != "ab" {
+		t.Errorf("expected login ab, got %v, %v", params, err)
+	}
+}
@@ -0,0 +1,118 @@
+package runtime
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Limit запросов за Per
+type Rate struct {
+	Limit int
+	Per   time.Duration
+}
+
+// Решает, можно ли выполнить запрос с ключом key при ограничении rate, и если нельзя - через сколько повторить.
+// Корзины в памяти не видят запросов к другим экземплярам сервиса, для них обработчик реализует Allow
+// поверх общего хранилища
+type Limiter interface {
+	Allow(key string, rate Rate) (bool, time.Duration)
+}
+
+// Корзины в памяти процесса, одни на все обработчики: ключ начинается с обработчика и метода
+var DefaultLimiter Limiter = &MemoryLimiter{}
+
+// Token bucket в памяти процесса: на ключ rate.Limit запросов сразу, дальше по одному каждые rate.Per / rate.Limit.
+// Корзина появляется при первом запросе с ключом
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	// Для тестов, nil - time.Now
+	now func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	rate   Rate
+}
+
+// Корзины, которые успели наполниться, удаляются раз в sweepInterval: новая корзина будет такой же
+const sweepInterval = time.Minute
+
+func (m *MemoryLimiter) Allow(key string, rate Rate) (bool, time.Duration) {
+	now := time.Now()
+	if m.now != nil {
+		now = m.now()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.buckets == nil {
+		m.buckets = map[string]*tokenBucket{}
+		m.lastSweep = now
+	}
+	if now.Sub(m.lastSweep) > sweepInterval {
+		for k, b := range m.buckets {
+			if b.refill(now) >= float64(b.rate.Limit) {
+				delete(m.buckets, k)
+			}
+		}
+		m.lastSweep = now
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(rate.Limit), last: now, rate: rate}
+		m.buckets[key] = b
+	}
+	b.rate = rate
+	b.tokens = b.refill(now)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) * float64(rate.Per) / float64(rate.Limit))
+}
+
+// Токены на момент now, не больше rate.Limit
+func (b *tokenBucket) refill(now time.Time) float64 {
+	tokens := b.tokens + float64(now.Sub(b.last))*float64(b.rate.Limit)/float64(b.rate.Per)
+	return math.Min(tokens, float64(b.rate.Limit))
+}
+
+// Ключ ограничения: endpoint - обработчик, метод и url ("MyApi.Create /user/create"), чтобы обработчики
+// с одним url не делили корзины, и ID автора запроса, если он есть, иначе IP клиента
+func RateLimitKey(endpoint string, r *http.Request, principal *Principal) string {
+	if principal != nil {
+		return endpoint + " auth:" + principal.ID
+	}
+	return endpoint + " ip:" + ClientIP(r)
+}
+
+// IP из RemoteAddr. X-Forwarded-For не учитывается: его может прислать любой клиент
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Спрашивает limiter и при отказе ставит заголовок Retry-After в секундах, округлённых вверх
+func AllowRequest(limiter Limiter, w http.ResponseWriter, key string, rate Rate) bool {
+	ok, retryAfter := limiter.Allow(key, rate)
+	if !ok {
+		seconds := int64(math.Ceil(retryAfter.Seconds()))
+		if seconds < 1 {
+			seconds = 1
+		}
+		w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	}
+	return ok
+}
@@ -0,0 +1,43 @@
+package runtime
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := &MemoryLimiter{now: func() time.Time { return now }}
+	rate := Rate{Limit: 2, Per: time.Second}
+
+	cases := []struct {
+		After      time.Duration
+		Key        string
+		Allowed    bool
+		RetryAfter time.Duration
+	}{
+		{0, "a", true, 0},
+		{0, "a", true, 0},
+		{0, "a", false, 500 * time.Millisecond},
+		// у другого ключа своя корзина
+		{0, "b", true, 0},
+		{200 * time.Millisecond, "a", false, 300 * time.Millisecond},
+		{300 * time.Millisecond, "a", true, 0},
+		// за время простоя токенов не больше Limit
+		{time.Hour, "a", true, 0},
+		{0, "a", true, 0},
+		{0, "a", false, 500 * time.Millisecond},
+	}
+
+	for idx, item := range cases {
+		now = now.Add(item.After)
+		allowed, retryAfter := limiter.Allow(item.Key, rate)
+		if allowed != item.Allowed || retryAfter != item.RetryAfter {
+			t.Errorf("[%d] expected %v %v, got %v %v", idx, item.Allowed, item.RetryAfter, allowed, retryAfter)
+		}
+	}
+
+	if _, ok := limiter.buckets["b"]; ok {
+		t.Errorf("expected full bucket to be swept")
+	}
+}
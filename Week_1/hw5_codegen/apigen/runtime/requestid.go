@@ -0,0 +1,96 @@
+package runtime
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	RequestIDHeader   = "X-Request-ID"
+	TraceparentHeader = "traceparent"
+)
+
+type requestIDContextKey struct{}
+
+// Берёт ID запроса из X-Request-ID, иначе trace-id из W3C traceparent, иначе создаёт новый.
+// Возвращает запрос с ID в контексте, ID и пришедший traceparent пишет в заголовки ответа
+func PropagateRequestID(w http.ResponseWriter, r *http.Request) *http.Request {
+	id, ok := incomingRequestID(r)
+	if !ok {
+		id = newRequestID()
+	}
+	w.Header().Set(RequestIDHeader, id)
+	if _, ok := parseTraceparent(r.Header.Get(TraceparentHeader)); ok {
+		w.Header().Set(TraceparentHeader, r.Header.Get(TraceparentHeader))
+	}
+	return r.WithContext(context.WithValue(r.Context(), requestIDContextKey{}, id))
+}
+
+// ID запроса, который положила обёртка
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDContextKey{}).(string)
+	return id, ok
+}
+
+// ID, который прислал клиент: ответы с ошибкой повторяют его в теле
+func incomingRequestID(r *http.Request) (string, bool) {
+	if id := r.Header.Get(RequestIDHeader); validRequestID(id) {
+		return id, true
+	}
+	return parseTraceparent(r.Header.Get(TraceparentHeader))
+}
+
+// До 128 видимых ASCII-символов, чтобы ID можно было без экранирования писать в заголовки и логи
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// trace-id из "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+func parseTraceparent(value string) (string, bool) {
+	parts := strings.Split(value, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return "", false
+	}
+	traceID, parentID := parts[1], parts[2]
+	if !lowerHex(parts[0], 2) || !lowerHex(traceID, 32) || !lowerHex(parentID, 16) || !lowerHex(parts[3], 2) {
+		return "", false
+	}
+	if traceID == strings.Repeat("0", 32) || parentID == strings.Repeat("0", 16) {
+		return "", false
+	}
+	return traceID, true
+}
+
+func lowerHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !('0' <= s[i] && s[i] <= '9' || 'a' <= s[i] && s[i] <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// 32 hex-символа, как trace-id
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,32 @@
+package runtime
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Ответ сгенерированного обработчика: {"error": "...", "response": ...}
+type Result struct {
+	Error    string       `json:"error" xml:"error"`
+	Errors   []FieldError `json:"errors,omitempty" xml:"errors,omitempty"`
+	Response interface{}  `json:"response,omitempty" xml:"response,omitempty"`
+	// ID запроса в ответах с ошибкой
+	RequestID string `json:"request_id,omitempty" xml:"request_id,omitempty"`
+}
+
+func Marshal(res Result) []byte {
+	resMap := make(map[string]interface{})
+	resMap["error"] = res.Error
+	if len(res.Errors) > 0 {
+		resMap["errors"] = res.Errors
+	}
+	if res.Response != nil {
+		resMap["response"] = res.Response
+	}
+	resultStr, _ := json.Marshal(resMap)
+	return resultStr
+}
+
+func WriteResponse(w http.ResponseWriter, response []byte) {
+	_, _ = w.Write(response)
+}
@@ -0,0 +1,31 @@
+// Package runtime - общий код для обработчиков, сгенерированных handlers_gen.
+//
+// Сгенерированный файл импортирует пакет и проверяет при компиляции, что пакет
+// поддерживает нужную ему версию API: const _ = runtime.APIVersion8.
+// Когда генератору нужно новое API, добавляется константа APIVersionN. Всё, на что ссылается
+// код объявленных версий, не удаляется и не меняется несовместимо, поэтому файлы,
+// сгенерированные раньше, компилируются и с новой версией пакета.
+package runtime
+
+// Последняя версия API, её использует код, сгенерированный текущим handlers_gen
+const Version = 8
+
+// Ссылка на APIVersionN из сгенерированного кода не скомпилируется, если версия N больше не поддерживается
+const (
+	// Result, Marshal, WriteResponse, Authenticator, ReadParams, ErrBodyTooLarge, FieldError, проверки format
+	APIVersion1 = true
+	// Encoder
+	APIVersion2 = true
+	// Acceptable, Format
+	APIVersion3 = true
+	// Logger, RecoverPanic
+	APIVersion4 = true
+	// Metrics, StatusWriter
+	APIVersion5 = true
+	// PropagateRequestID, RequestIDFromContext, RequestID у встроенных Encoder
+	APIVersion6 = true
+	// Limiter, Rate, RateLimitKey, AllowRequest
+	APIVersion7 = true
+	// Cache, CacheKey, WriteWithETag
+	APIVersion8 = true
+)
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	apigen "github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/apigen/runtime"
+)
+
+// Сравнение сгенерированной проверки CreateParams с прежней версией,
+// которая разбирала min/max и собирала enum на каждый запрос
+
+func BenchmarkValidateAndBuildCreateParams(b *testing.B) {
+	params := url.Values{
+		"login":     {"new_moderator"},
+		"full_name": {"Ivan Ivanov"},
+		"status":    {"moderator"},
+		"age":       {"32"},
+	}
+	ctx := context.Background()
+
+	b.Run("generated", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := validateAndBuildCreateParams(ctx, nil, params, false); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("legacy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := legacyValidateAndBuildCreateParams(ctx, nil, params, false); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestLegacyValidateAndBuildCreateParams(t *testing.T) {
+	cases := []url.Values{
+		{"login": {"new_moderator"}, "age": {"32"}},
+		{"login": {"new_moderator"}, "age": {"32"}, "status": {"adm"}},
+		{"login": {"short"}, "age": {"32"}},
+		{"login": {"new_moderator"}, "age": {"ten"}},
+		{"login": {"new_moderator"}, "age": {"129"}},
+		{"age": {"-1"}, "status": {"adm"}},
+	}
+	ctx := context.Background()
+
+	for idx, params := range cases {
+		for _, collectAll := range []bool{false, true} {
+			res, err := validateAndBuildCreateParams(ctx, nil, params, collectAll)
+			legacyRes, legacyErr := legacyValidateAndBuildCreateParams(ctx, nil, params, collectAll)
+			if fmt.Sprint(res, err) != fmt.Sprint(legacyRes, legacyErr) {
+				t.Errorf("[%d] results not match\nGot: %v %v\nExpected: %v %v", idx, res, err, legacyRes, legacyErr)
+			}
+		}
+	}
+}
+
+func legacyValidateAndBuildCreateParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*CreateParams, error) {
+	res := CreateParams{}
+
+	var paramName string
+	var paramValue string
+	var required bool
+	var defaultValue string
+	var enum []string
+
+	var errs apigen.ValidationErrors
+	var err error
+	paramName = "login"
+
+	required = true
+	defaultValue = ""
+
+	enum = make([]string, 0)
+
+	err = func() error {
+		paramValue = params.Get(paramName)
+
+		if required && paramValue == "" {
+			return apigen.FieldError{Param: paramName, Rule: "required", Message: paramName + " must me not empty"}
+		}
+
+		if paramValue == "" && defaultValue != "" {
+			paramValue = defaultValue
+		}
+
+		if len(enum) > 0 && !legacyContains(enum, paramValue) {
+			return apigen.FieldError{Param: paramName, Rule: "enum", Message: paramName + " must be one of " + legacyPrintSlice(enum)}
+		}
+
+		LoginVal := paramValue
+
+		if err := legacyValidateMinMaxStr(LoginVal, paramName, "10", ""); err != nil {
+			return err
+		}
+
+		res.Login = LoginVal
+
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	paramName = "full_name"
+
+	required = false
+	defaultValue = ""
+
+	enum = make([]string, 0)
+
+	err = func() error {
+		paramValue = params.Get(paramName)
+
+		if required && paramValue == "" {
+			return apigen.FieldError{Param: paramName, Rule: "required", Message: paramName + " must me not empty"}
+		}
+
+		if paramValue == "" && defaultValue != "" {
+			paramValue = defaultValue
+		}
+
+		if len(enum) > 0 && !legacyContains(enum, paramValue) {
+			return apigen.FieldError{Param: paramName, Rule: "enum", Message: paramName + " must be one of " + legacyPrintSlice(enum)}
+		}
+
+		NameVal := paramValue
+
+		if err := legacyValidateMinMaxStr(NameVal, paramName, "", ""); err != nil {
+			return err
+		}
+
+		res.Name = NameVal
+
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	paramName = "status"
+
+	required = false
+	defaultValue = "user"
+
+	enum = make([]string, 0)
+	enum = append(enum, "user")
+	enum = append(enum, "moderator")
+	enum = append(enum, "admin")
+
+	err = func() error {
+		paramValue = params.Get(paramName)
+
+		if required && paramValue == "" {
+			return apigen.FieldError{Param: paramName, Rule: "required", Message: paramName + " must me not empty"}
+		}
+
+		if paramValue == "" && defaultValue != "" {
+			paramValue = defaultValue
+		}
+
+		if len(enum) > 0 && !legacyContains(enum, paramValue) {
+			return apigen.FieldError{Param: paramName, Rule: "enum", Message: paramName + " must be one of " + legacyPrintSlice(enum)}
+		}
+
+		StatusVal := paramValue
+
+		if err := legacyValidateMinMaxStr(StatusVal, paramName, "", ""); err != nil {
+			return err
+		}
+
+		res.Status = StatusVal
+
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	paramName = "age"
+
+	required = false
+	defaultValue = ""
+
+	enum = make([]string, 0)
+
+	err = func() error {
+		paramValue = params.Get(paramName)
+
+		if required && paramValue == "" {
+			return apigen.FieldError{Param: paramName, Rule: "required", Message: paramName + " must me not empty"}
+		}
+
+		if paramValue == "" && defaultValue != "" {
+			paramValue = defaultValue
+		}
+
+		if len(enum) > 0 && !legacyContains(enum, paramValue) {
+			return apigen.FieldError{Param: paramName, Rule: "enum", Message: paramName + " must be one of " + legacyPrintSlice(enum)}
+		}
+
+		AgeVal, err := strconv.Atoi(paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: paramName, Rule: "type", Message: paramName + " must be int"}
+		}
+
+		if err := legacyValidateMinMaxInt(AgeVal, paramName, "0", "128"); err != nil {
+			return err
+		}
+
+		res.Age = AgeVal
+
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
+
+func legacyPrintSlice(s []string) string {
+	return "[" + strings.Join(s, ", ") + "]"
+}
+
+func legacyValidateMinMaxInt(value int, valueName, min, max string) error {
+	if min != "" {
+		minInt, err := strconv.Atoi(min)
+		if err != nil {
+			return err
+		}
+		if value < minInt {
+			return apigen.FieldError{Param: valueName, Rule: "min", Message: valueName + " must be >= " + min}
+		}
+	}
+
+	if max != "" {
+		maxInt, err := strconv.Atoi(max)
+		if err != nil {
+			return err
+		}
+		if value > maxInt {
+			return apigen.FieldError{Param: valueName, Rule: "max", Message: valueName + " must be <= " + max}
+		}
+	}
+
+	return nil
+}
+
+func legacyValidateMinMaxStr(value, valueName, min, max string) error {
+	if min != "" {
+		minInt, err := strconv.Atoi(min)
+		if err != nil {
+			return err
+		}
+		if len(value) < minInt {
+			return apigen.FieldError{Param: valueName, Rule: "min", Message: valueName + " len must be >= " + min}
+		}
+	}
+
+	if max != "" {
+		maxInt, err := strconv.Atoi(max)
+		if err != nil {
+			return err
+		}
+		if len(value) > maxInt {
+			return apigen.FieldError{Param: valueName, Rule: "max", Message: valueName + " len must be <= " + max}
+		}
+	}
+
+	return nil
+}
+
+func legacyContains(arr []string, item string) bool {
+	for _, i := range arr {
+		if item == i {
+			return true
+		}
+	}
+	return false
+}
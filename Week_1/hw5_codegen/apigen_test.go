@@ -0,0 +1,583 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/apiclient"
+	apigen "github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/apigen/runtime"
+)
+
+// Проверки возможностей кодогенератора, которых нет в main_test.go
+
+type JsonCase struct {
+	Method  string
+	Path    string
+	Body    string
+	Headers map[string]string
+	Status  int
+	Result  interface{}
+}
+
+func TestMyApiJsonBody(t *testing.T) {
+	ts := httptest.NewServer(NewMyApi())
+	defer ts.Close()
+
+	cases := []JsonCase{
+		JsonCase{
+			Method: http.MethodPost,
+			Path:   ApiUserCreate,
+			Body:   `{"login": "json_moderator", "age": 32, "status": "moderator", "full_name": "Json Ivanov"}`,
+			Status: http.StatusOK,
+			Result: CR{
+				"error": "",
+				"response": CR{
+					"id": 43,
+				},
+			},
+		},
+		JsonCase{
+			Method: http.MethodPost,
+			Path:   ApiUserProfile,
+			Body:   `{"login": "json_moderator"}`,
+			Status: http.StatusOK,
+			Result: CR{
+				"error": "",
+				"response": CR{
+					"id":        43,
+					"login":     "json_moderator",
+					"full_name": "Json Ivanov",
+					"status":    10,
+				},
+			},
+		},
+		JsonCase{ // status по-умолчанию
+			Method: http.MethodPost,
+			Path:   ApiUserCreate,
+			Body:   `{"login": "json_default_user", "age": 10}`,
+			Status: http.StatusOK,
+			Result: CR{
+				"error": "",
+				"response": CR{
+					"id": 44,
+				},
+			},
+		},
+		JsonCase{
+			Method: http.MethodPost,
+			Path:   ApiUserCreate,
+			Body:   `{"login": "json", "age": 32}`,
+			Status: http.StatusBadRequest,
+			Result: CR{
+				"error": "login len must be >= 10",
+			},
+		},
+		JsonCase{
+			Method: http.MethodPost,
+			Path:   ApiUserCreate,
+			Body:   `{"login": "json_moderator2", "age": 32.5}`,
+			Status: http.StatusBadRequest,
+			Result: CR{
+				"error": "age must be int",
+			},
+		},
+		JsonCase{
+			Method: http.MethodPost,
+			Path:   ApiUserCreate,
+			Body:   `{"login": "json_moderator2", "age": 32, "status": "adm"}`,
+			Status: http.StatusBadRequest,
+			Result: CR{
+				"error": "status must be one of [user, moderator, admin]",
+			},
+		},
+		JsonCase{
+			Method: http.MethodPost,
+			Path:   ApiUserCreate,
+			Body:   `{"login": `,
+			Status: http.StatusBadRequest,
+			Result: CR{
+				"error": "invalid json body",
+			},
+		},
+		JsonCase{
+			Method: http.MethodPost,
+			Path:   ApiUserCreate,
+			Body:   `{"login": "json_` + strings.Repeat("x", 1024) + `", "age": 32}`,
+			Status: http.StatusRequestEntityTooLarge,
+			Result: CR{
+				"error": "request body too large",
+			},
+		},
+	}
+
+	maxBodySize := apigen.MaxJSONBodySize
+	apigen.MaxJSONBodySize = 1024
+	defer func() { apigen.MaxJSONBodySize = maxBodySize }()
+
+	runJsonTests(t, ts, cases)
+}
+
+func TestMyApiFormBodyLimit(t *testing.T) {
+	ts := httptest.NewServer(NewMyApi())
+	defer ts.Close()
+
+	maxBodySize := apigen.MaxFormBodySize
+	apigen.MaxFormBodySize = 1024
+	defer func() { apigen.MaxFormBodySize = maxBodySize }()
+
+	runTests(t, ts, []Case{
+		Case{
+			Method: http.MethodPost,
+			Path:   ApiUserProfile,
+			Query:  "login=rvasily",
+			Status: http.StatusOK,
+			Result: CR{
+				"error": "",
+				"response": CR{
+					"id":        42,
+					"login":     "rvasily",
+					"full_name": "Vasily Romanov",
+					"status":    20,
+				},
+			},
+		},
+		// форма больше MaxFormBodySize - 413, как JSON
+		Case{
+			Method: http.MethodPost,
+			Path:   ApiUserProfile,
+			Query:  "login=rvasily&padding=" + strings.Repeat("x", 1024),
+			Status: http.StatusRequestEntityTooLarge,
+			Result: CR{
+				"error": "request body too large",
+			},
+		},
+	})
+}
+
+func TestMyApiFieldsOrder(t *testing.T) {
+	ts := httptest.NewServer(NewMyApi())
+	defer ts.Close()
+
+	cases := []Case{
+		Case{ // ошибки параметров в порядке следования полей: Login раньше Age
+			Path:   ApiUserCreate,
+			Method: http.MethodPost,
+			Query:  "age=ten&status=moderator",
+			Status: http.StatusBadRequest,
+			Auth:   true,
+			Result: CR{
+				"error": "login must me not empty",
+			},
+		},
+		Case{ // Status раньше Age
+			Path:   ApiUserCreate,
+			Method: http.MethodPost,
+			Query:  "login=new_moderator&age=ten&status=adm",
+			Status: http.StatusBadRequest,
+			Auth:   true,
+			Result: CR{
+				"error": "status must be one of [user, moderator, admin]",
+			},
+		},
+	}
+
+	runTests(t, ts, cases)
+}
+
+func TestCreateParamsCollectAllErrors(t *testing.T) {
+	params := url.Values{
+		"age":    {"ten"},
+		"status": {"adm"},
+	}
+
+	_, err := validateAndBuildCreateParams(context.Background(), nil, params, false)
+	if err == nil || err.Error() != "login must me not empty" {
+		t.Errorf("expected first error only, got %v", err)
+	}
+
+	_, err = validateAndBuildCreateParams(context.Background(), nil, params, true)
+	expected := apigen.ValidationErrors{
+		{Param: "login", Rule: "required", Message: "login must me not empty"},
+		{Param: "status", Rule: "enum", Message: "status must be one of [user, moderator, admin]"},
+		{Param: "age", Rule: "type", Message: "age must be int"},
+	}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("results not match\nGot: %#v\nExpected: %#v", err, expected)
+	}
+
+	params = url.Values{"login": {"new_moderator"}, "age": {"129"}}
+	_, err = validateAndBuildCreateParams(context.Background(), nil, params, true)
+	expected = apigen.ValidationErrors{{Param: "age", Rule: "max", Message: "age must be <= 128"}}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("results not match\nGot: %#v\nExpected: %#v", err, expected)
+	}
+
+	params.Set("age", "32")
+	if res, err := validateAndBuildCreateParams(context.Background(), nil, params, true); err != nil || res.Status != "user" {
+		t.Errorf("unexpected result: %#v, %v", res, err)
+	}
+}
+
+type bearerAuthenticator struct{}
+
+func (bearerAuthenticator) Authenticate(r *http.Request) (*apigen.Principal, error) {
+	if r.Header.Get("Authorization") != "Bearer moderator" {
+		return nil, ApiError{http.StatusUnauthorized, fmt.Errorf("bad token")}
+	}
+	return &apigen.Principal{ID: "moderator"}, nil
+}
+
+func TestMyApiCustomAuthenticator(t *testing.T) {
+	defaultAuthenticator := apigen.DefaultAuthenticator
+	apigen.DefaultAuthenticator = bearerAuthenticator{}
+	defer func() {
+		apigen.DefaultAuthenticator = defaultAuthenticator
+	}()
+
+	ts := httptest.NewServer(NewMyApi())
+	defer ts.Close()
+
+	cases := []JsonCase{
+		JsonCase{
+			Method:  http.MethodPost,
+			Path:    ApiUserCreate,
+			Body:    `{"login": "bearer_moderator", "age": 32}`,
+			Headers: map[string]string{"Authorization": "Bearer moderator"},
+			Status:  http.StatusOK,
+			Result: CR{
+				"error": "",
+				"response": CR{
+					"id": 43,
+				},
+			},
+		},
+		JsonCase{ // X-Auth больше не проверяется, ошибка ApiError отдаётся со своим статусом
+			Method: http.MethodPost,
+			Path:   ApiUserCreate,
+			Body:   `{"login": "bearer_moderator2", "age": 32}`,
+			Status: http.StatusUnauthorized,
+			Result: CR{
+				"error": "bad token",
+			},
+		},
+	}
+
+	runJsonTests(t, ts, cases)
+}
+
+func TestMyApiContentNegotiation(t *testing.T) {
+	ts := httptest.NewServer(NewMyApi())
+	defer ts.Close()
+
+	cases := []struct {
+		Accept      string
+		Status      int
+		ContentType string
+		Body        string
+	}{
+		{"", http.StatusOK, "application/json",
+			`{"error":"","response":{"id":42,"login":"rvasily","full_name":"Vasily Romanov","status":20}}`},
+		{"application/xml", http.StatusOK, "application/xml", xml.Header +
+			`<result><error></error><response><ID>42</ID><Login>rvasily</Login><FullName>Vasily Romanov</FullName><Status>20</Status></response></result>`},
+		{"text/html, application/msgpack;q=0.5", http.StatusOK, "application/msgpack",
+			"\x82\xa5error\xa0\xa8response\x84\xa9full_name\xaeVasily Romanov\xa2id*\xa5login\xa7rvasily\xa6status\x14"},
+		{"text/html", http.StatusNotAcceptable, "application/json", `{"error":"not acceptable"}`},
+		// application/problem+json - тип только для ошибок ProblemEncoder, не для ответа метода
+		{"application/problem+json", http.StatusNotAcceptable, "application/json", `{"error":"not acceptable"}`},
+	}
+
+	for idx, item := range cases {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+ApiUserProfile+"?login=rvasily", nil)
+		if item.Accept != "" {
+			req.Header.Set("Accept", item.Accept)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Errorf("[%d] request error: %v", idx, err)
+			continue
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != item.Status {
+			t.Errorf("[%d] expected http status %v, got %v", idx, item.Status, resp.StatusCode)
+		}
+		if contentType := resp.Header.Get("Content-Type"); contentType != item.ContentType {
+			t.Errorf("[%d] expected content type %q, got %q", idx, item.ContentType, contentType)
+		}
+		if string(body) != item.Body {
+			t.Errorf("[%d] results not match\nGot: %q\nExpected: %q", idx, body, item.Body)
+		}
+	}
+}
+
+const profileRvasily = `{"error":"","response":{"id":42,"login":"rvasily","full_name":"Vasily Romanov","status":20}}`
+
+func TestMyApiMethods(t *testing.T) {
+	ts := httptest.NewServer(NewMyApi())
+	defer ts.Close()
+
+	cases := []struct {
+		Method string
+		Path   string
+		Status int
+		Allow  string
+		Body   string
+	}{
+		{http.MethodOptions, ApiUserCreate, http.StatusNoContent, "POST, OPTIONS", ""},
+		{http.MethodGet, ApiUserCreate, http.StatusNotAcceptable, "POST, OPTIONS", `{"error":"bad method"}`},
+		// тело ответа на HEAD net/http не отправляет
+		{http.MethodHead, ApiUserProfile + "?login=rvasily", http.StatusOK, "", ""},
+		// без ограничения принимается любой метод, на OPTIONS обёртка отвечает сама, не вызывая метод
+		{http.MethodOptions, ApiUserProfile, http.StatusNoContent, "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS", ""},
+		{http.MethodOptions, ApiUserProfile + "?login=rvasily", http.StatusNoContent, "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS", ""},
+		{http.MethodPut, ApiUserProfile + "?login=rvasily", http.StatusOK, "", profileRvasily},
+	}
+
+	for idx, item := range cases {
+		req, _ := http.NewRequest(item.Method, ts.URL+item.Path, nil)
+		req.Header.Set("X-Auth", "100500")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Errorf("[%d] request error: %v", idx, err)
+			continue
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != item.Status {
+			t.Errorf("[%d] expected http status %v, got %v", idx, item.Status, resp.StatusCode)
+		}
+		if allow := resp.Header.Get("Allow"); allow != item.Allow {
+			t.Errorf("[%d] expected Allow %q, got %q", idx, item.Allow, allow)
+		}
+		if string(body) != item.Body {
+			t.Errorf("[%d] results not match\nGot: %q\nExpected: %q", idx, body, item.Body)
+		}
+	}
+}
+
+func TestMyApiPanicAndErrorLog(t *testing.T) {
+	var logs strings.Builder
+	defaultLogger := apigen.DefaultLogger
+	apigen.DefaultLogger = apigen.StdLogger{Logger: log.New(&logs, "", 0)}
+	defer func() { apigen.DefaultLogger = defaultLogger }()
+
+	// без NewMyApi у MyApi нет mutex и Profile паникует
+	ts := httptest.NewServer(&MyApi{})
+	defer ts.Close()
+
+	cases := []struct {
+		Path   string
+		Status int
+		Body   string
+		Log    string
+	}{
+		{ApiUserProfile + "?login=rvasily", http.StatusInternalServerError, `{"error":"internal error","request_id":"req-1"}`,
+			`handler=MyApi.Profile request_id=req-1 method=GET path="/user/profile" remote=127.0.0.1`},
+		{ApiUserProfile + "?login=bad_user", http.StatusInternalServerError, `{"error":"bad user","request_id":"req-1"}`,
+			`status=500 error="bad user"`},
+	}
+
+	for idx, item := range cases {
+		logs.Reset()
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+item.Path, nil)
+		req.Header.Set("X-Request-ID", "req-1")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Errorf("[%d] request error: %v", idx, err)
+			continue
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != item.Status {
+			t.Errorf("[%d] expected http status %v, got %v", idx, item.Status, resp.StatusCode)
+		}
+		if string(body) != item.Body {
+			t.Errorf("[%d] expected body %s, got %s", idx, item.Body, body)
+		}
+		if !strings.Contains(logs.String(), item.Log) {
+			t.Errorf("[%d] expected log with %s, got %s", idx, item.Log, logs.String())
+		}
+	}
+}
+
+func TestMyApiMetrics(t *testing.T) {
+	metrics := &apigen.MemoryMetrics{}
+	defaultMetrics := apigen.DefaultMetrics
+	apigen.DefaultMetrics = metrics
+	defer func() { apigen.DefaultMetrics = defaultMetrics }()
+
+	ts := httptest.NewServer(NewMyApi())
+	defer ts.Close()
+
+	for _, path := range []string{
+		ApiUserProfile + "?login=rvasily",
+		ApiUserProfile + "?login=rvasily",
+		ApiUserProfile + "?login=nobody",
+		ApiUserProfile,
+	} {
+		resp, err := client.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("request error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	text := rec.Body.String()
+	for _, line := range []string{
+		`apigen_requests_total{url="/user/profile",status="200"} 2`,
+		`apigen_requests_total{url="/user/profile",status="400"} 1`,
+		`apigen_requests_total{url="/user/profile",status="404"} 1`,
+		`apigen_request_duration_seconds_count{url="/user/profile"} 4`,
+		`apigen_validation_failures_total{url="/user/profile"} 1`,
+	} {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("expected metrics line %s, got:\n%s", line, text)
+		}
+	}
+}
+
+func TestMyApiRequestID(t *testing.T) {
+	ts := httptest.NewServer(NewMyApi())
+	defer ts.Close()
+
+	cases := []struct {
+		Headers     map[string]string
+		ID          string
+		Traceparent string
+		Body        string
+	}{
+		// без заголовков ID создаётся и в тело ошибки не попадает
+		{nil, "", "", `{"error":"user not exist"}`},
+		{map[string]string{"X-Request-ID": "support-42"}, "support-42", "",
+			`{"error":"user not exist","request_id":"support-42"}`},
+		{map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			"4bf92f3577b34da6a3ce929d0e0e4736", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			`{"error":"user not exist","request_id":"4bf92f3577b34da6a3ce929d0e0e4736"}`},
+		{map[string]string{"traceparent": "00-00000000000000000000000000000000-00f067aa0ba902b7-01"}, "", "",
+			`{"error":"user not exist"}`},
+	}
+
+	for idx, item := range cases {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+ApiUserProfile+"?login=nobody", nil)
+		for key, value := range item.Headers {
+			req.Header.Set(key, value)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Errorf("[%d] request error: %v", idx, err)
+			continue
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		id := resp.Header.Get("X-Request-ID")
+		if item.ID == "" && len(id) != 32 || item.ID != "" && id != item.ID {
+			t.Errorf("[%d] expected X-Request-ID %q, got %q", idx, item.ID, id)
+		}
+		if got := resp.Header.Get("traceparent"); got != item.Traceparent {
+			t.Errorf("[%d] expected traceparent %q, got %q", idx, item.Traceparent, got)
+		}
+		if string(body) != item.Body {
+			t.Errorf("[%d] expected body %s, got %s", idx, item.Body, body)
+		}
+	}
+}
+
+func TestMyApiClient(t *testing.T) {
+	ts := httptest.NewServer(NewMyApi())
+	defer ts.Close()
+
+	ctx := context.Background()
+	api := apiclient.NewMyApiClient(ts.URL, "100500")
+
+	user, err := api.Profile(ctx, apiclient.ProfileParams{Login: "rvasily"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedUser := &apiclient.User{ID: 42, Login: "rvasily", FullName: "Vasily Romanov", Status: 20}
+	if !reflect.DeepEqual(user, expectedUser) {
+		t.Errorf("results not match\nGot: %#v\nExpected: %#v", user, expectedUser)
+	}
+
+	newUser, err := api.Create(ctx, apiclient.CreateParams{Login: "client_moderator", Name: "Client Ivanov", Age: 32})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if newUser.ID != 43 {
+		t.Errorf("expected id 43, got %d", newUser.ID)
+	}
+
+	if _, err = api.Profile(ctx, apiclient.ProfileParams{Login: "not_exist_user"}); !isClientError(err, http.StatusNotFound, "user not exist") {
+		t.Errorf("unexpected error: %#v", err)
+	}
+	if _, err = api.Create(ctx, apiclient.CreateParams{Login: "client", Age: 32}); !isClientError(err, http.StatusBadRequest, "login len must be >= 10") {
+		t.Errorf("unexpected error: %#v", err)
+	}
+
+	unauthorized := apiclient.NewMyApiClient(ts.URL, "")
+	if _, err = unauthorized.Create(ctx, apiclient.CreateParams{Login: "client_moderator2", Age: 32}); !isClientError(err, http.StatusForbidden, "unauthorized") {
+		t.Errorf("unexpected error: %#v", err)
+	}
+}
+
+func isClientError(err error, status int, message string) bool {
+	apiErr, ok := err.(apiclient.ApiError)
+	return ok && apiErr.HTTPStatus == status && apiErr.Error() == message
+}
+
+func runJsonTests(t *testing.T, ts *httptest.Server, cases []JsonCase) {
+	for idx, item := range cases {
+		req, err := http.NewRequest(item.Method, ts.URL+item.Path, strings.NewReader(item.Body))
+		if err != nil {
+			t.Fatalf("[%d] cant create request: %v", idx, err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Auth", "100500")
+		for key, value := range item.Headers {
+			req.Header.Set(key, value)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Errorf("[%d] request error: %v", idx, err)
+			continue
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != item.Status {
+			t.Errorf("[%d] expected http status %v, got %v", idx, item.Status, resp.StatusCode)
+			continue
+		}
+
+		var result, expected interface{}
+		if err = json.Unmarshal(body, &result); err != nil {
+			t.Errorf("[%d] cant unpack json: %v", idx, err)
+			continue
+		}
+
+		data, _ := json.Marshal(item.Result)
+		_ = json.Unmarshal(data, &expected)
+
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("[%d] results not match\nGot: %#v\nExpected: %#v", idx, result, item.Result)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package fixture
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	apigen "github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/apigen/runtime"
+)
+
+type WhoParams struct{}
+
+// Authenticator в поле, nil - apigen.DefaultAuthenticator
+type FieldAuthApi struct {
+	Auth apigen.Authenticator
+	// Сколько раз вызван Moderate
+	Moderated int
+}
+
+// apigen:api {"url": "/who", "auth": true}
+func (api *FieldAuthApi) Who(ctx context.Context, in WhoParams) (*apigen.Principal, error) {
+	principal, _ := apigen.PrincipalFromContext(ctx)
+	return principal, nil
+}
+
+// apigen:api {"url": "/moderate", "auth": true, "roles": ["admin", "moderator"]}
+func (api *FieldAuthApi) Moderate(ctx context.Context, in WhoParams) (*apigen.Principal, error) {
+	api.Moderated++
+	principal, _ := apigen.PrincipalFromContext(ctx)
+	return principal, nil
+}
+
+// Сам реализует Authenticator: Authorization: Bearer <id>
+type SelfAuthApi struct{}
+
+func (api *SelfAuthApi) Authenticate(r *http.Request) (*apigen.Principal, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return nil, ApiError{http.StatusUnauthorized, errors.New("no token")}
+	}
+	if !strings.HasPrefix(header, "Bearer ") {
+		return nil, errors.New("bad token")
+	}
+	return &apigen.Principal{ID: strings.TrimPrefix(header, "Bearer ")}, nil
+}
+
+// apigen:api {"url": "/who", "auth": true}
+func (api *SelfAuthApi) Who(ctx context.Context, in WhoParams) (*apigen.Principal, error) {
+	principal, _ := apigen.PrincipalFromContext(ctx)
+	return principal, nil
+}
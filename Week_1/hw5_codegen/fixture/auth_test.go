@@ -0,0 +1,75 @@
+package fixture
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	apigen "github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/apigen/runtime"
+)
+
+func TestFieldAuthenticator(t *testing.T) {
+	api := &FieldAuthApi{Auth: apigen.HeaderAuthenticator{Header: "X-Token", Token: "secret", ID: "ann", Roles: []string{"user"}}}
+	runCases(t, api, []Case{
+		{Path: "/who", Headers: map[string]string{"X-Token": "secret"}, Status: http.StatusOK,
+			Result: `{"error":"","response":{"ID":"ann","Roles":["user"]}}`},
+		{Path: "/who", Headers: map[string]string{"X-Token": "wrong"}, Status: http.StatusForbidden,
+			Result: `{"error":"unauthorized"}`},
+		// DefaultAuthenticator не используется, если поле задано
+		{Path: "/who", Headers: map[string]string{"X-Auth": "100500"}, Status: http.StatusForbidden,
+			Result: `{"error":"unauthorized"}`},
+	})
+
+	// nil в поле - DefaultAuthenticator
+	runCases(t, &FieldAuthApi{}, []Case{
+		{Path: "/who", Headers: map[string]string{"X-Auth": "100500"}, Status: http.StatusOK,
+			Result: `{"error":"","response":{"ID":"x-auth","Roles":null}}`},
+		{Path: "/who", Status: http.StatusForbidden,
+			Result: `{"error":"unauthorized"}`},
+	})
+}
+
+func TestSelfAuthenticator(t *testing.T) {
+	runCases(t, &SelfAuthApi{}, []Case{
+		{Path: "/who", Headers: map[string]string{"Authorization": "Bearer ann"}, Status: http.StatusOK,
+			Result: `{"error":"","response":{"ID":"ann","Roles":null}}`},
+		// ApiError отдаётся со своим статусом, остальные ошибки - с 403
+		{Path: "/who", Status: http.StatusUnauthorized,
+			Result: `{"error":"no token"}`},
+		{Path: "/who", Headers: map[string]string{"Authorization": "Basic ann"}, Status: http.StatusForbidden,
+			Result: `{"error":"bad token"}`},
+		{Path: "/who", Headers: map[string]string{"X-Auth": "100500"}, Status: http.StatusUnauthorized,
+			Result: `{"error":"no token"}`},
+	})
+}
+
+func TestRoles(t *testing.T) {
+	api := &FieldAuthApi{Auth: roleAuthenticator{}}
+	runCases(t, api, []Case{
+		{Path: "/moderate", Headers: map[string]string{"X-Roles": "moderator"}, Status: http.StatusOK,
+			Result: `{"error":"","response":{"ID":"ann","Roles":["moderator"]}}`},
+		{Path: "/moderate", Headers: map[string]string{"X-Roles": "user,admin"}, Status: http.StatusOK,
+			Result: `{"error":"","response":{"ID":"ann","Roles":["user","admin"]}}`},
+		{Path: "/moderate", Headers: map[string]string{"X-Roles": "user"}, Status: http.StatusForbidden,
+			Result: `{"error":"forbidden"}`},
+		{Path: "/moderate", Status: http.StatusForbidden,
+			Result: `{"error":"forbidden"}`},
+		// без roles в аннотации роли не проверяются
+		{Path: "/who", Status: http.StatusOK,
+			Result: `{"error":"","response":{"ID":"ann","Roles":null}}`},
+	})
+	if api.Moderated != 2 {
+		t.Errorf("expected Moderate to be called 2 times, got %d", api.Moderated)
+	}
+}
+
+// Выдаёт автору запроса роли из заголовка X-Roles
+type roleAuthenticator struct{}
+
+func (roleAuthenticator) Authenticate(r *http.Request) (*apigen.Principal, error) {
+	principal := &apigen.Principal{ID: "ann"}
+	if roles := r.Header.Get("X-Roles"); roles != "" {
+		principal.Roles = strings.Split(roles, ",")
+	}
+	return principal, nil
+}
@@ -0,0 +1,85 @@
+package fixture
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	apigen "github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/apigen/runtime"
+)
+
+// Методы с "cache". Свой кеш, чтобы тесты не делили DefaultCache
+type CacheApi struct {
+	Auth  apigen.Authenticator
+	cache apigen.MemoryCache
+	// Сколько раз вызван каждый метод
+	Calls map[string]int
+}
+
+type CacheParams struct {
+	Login string `apivalidator:"default=ann"`
+}
+
+// Call - номер вызова метода: ответ из кеша повторяет номер того вызова, который его сохранил
+type CacheResult struct {
+	Login string
+	Call  int
+}
+
+func (api *CacheApi) Get(key string) (interface{}, bool) {
+	return api.cache.Get(key)
+}
+
+func (api *CacheApi) Set(key string, value interface{}, ttl time.Duration) {
+	api.cache.Set(key, value, ttl)
+}
+
+func (api *CacheApi) result(name, login string) (*CacheResult, error) {
+	if api.Calls == nil {
+		api.Calls = map[string]int{}
+	}
+	api.Calls[name]++
+	if login == "nobody" {
+		return nil, ApiError{http.StatusNotFound, errors.New("user not exist")}
+	}
+	return &CacheResult{Login: login, Call: api.Calls[name]}, nil
+}
+
+// Только ETag и Cache-Control, тело не зависит от номера вызова
+//
+// apigen:api {"url": "/cached", "cache": "30s"}
+func (api *CacheApi) Cached(ctx context.Context, in CacheParams) (*CacheParams, error) {
+	if _, err := api.result("Cached", in.Login); err != nil {
+		return nil, err
+	}
+	return &in, nil
+}
+
+// apigen:api {"url": "/cached/memory", "cache": {"ttl": "1m", "memory": true}}
+func (api *CacheApi) Memory(ctx context.Context, in CacheParams) (*CacheResult, error) {
+	return api.result("Memory", in.Login)
+}
+
+// Ответ для автора запроса
+//
+// apigen:api {"url": "/cached/private", "auth": true, "cache": {"ttl": "1m", "memory": true}}
+func (api *CacheApi) Private(ctx context.Context, in CacheParams) (*CacheResult, error) {
+	principal, _ := apigen.PrincipalFromContext(ctx)
+	return api.result("Private", principal.ID)
+}
+
+// Обработчики с одним url и общим apigen.DefaultCache
+type SameURLCacheApi struct{}
+
+type OtherSameURLCacheApi struct{}
+
+// apigen:api {"url": "/cached/same", "cache": {"ttl": "1m", "memory": true}}
+func (api *SameURLCacheApi) Same(ctx context.Context, in CacheParams) (*CacheResult, error) {
+	return &CacheResult{Login: in.Login, Call: 1}, nil
+}
+
+// apigen:api {"url": "/cached/same", "cache": {"ttl": "1m", "memory": true}}
+func (api *OtherSameURLCacheApi) Same(ctx context.Context, in CacheParams) (*CacheResult, error) {
+	return &CacheResult{Login: in.Login, Call: 2}, nil
+}
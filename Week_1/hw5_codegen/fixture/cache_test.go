@@ -0,0 +1,154 @@
+package fixture
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apigen "github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/apigen/runtime"
+)
+
+func TestCacheETag(t *testing.T) {
+	api := &CacheApi{}
+	ts := httptest.NewServer(api)
+	defer ts.Close()
+
+	get := func(path, ifNoneMatch string) (*http.Response, string) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		resp, err := ts.Client().Do(req)
+		if err != nil {
+			t.Fatalf("request error: %v", err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		return resp, string(body)
+	}
+
+	resp, body := get("/cached?login=ann", "")
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" || !sameBody([]byte(body), `{"error":"","response":{"Login":"ann"}}`) {
+		t.Fatalf("expected 200 with ETag, got %d %q %s", resp.StatusCode, etag, body)
+	}
+	if got := resp.Header.Get("Cache-Control"); got != "max-age=30" {
+		t.Errorf("expected Cache-Control max-age=30, got %q", got)
+	}
+
+	cases := []struct {
+		Path        string
+		IfNoneMatch string
+		Status      int
+	}{
+		{"/cached?login=ann", etag, http.StatusNotModified},
+		{"/cached?login=ann", "W/" + etag, http.StatusNotModified},
+		{"/cached?login=ann", `"other", ` + etag, http.StatusNotModified},
+		{"/cached?login=ann", `"other"`, http.StatusOK},
+		// у другого ответа другой ETag
+		{"/cached?login=bob", etag, http.StatusOK},
+		// ошибки пишутся как обычно
+		{"/cached?login=nobody", etag, http.StatusNotFound},
+	}
+	for idx, item := range cases {
+		resp, body := get(item.Path, item.IfNoneMatch)
+		if resp.StatusCode != item.Status {
+			t.Errorf("[%d] expected http status %d, got %d", idx, item.Status, resp.StatusCode)
+		}
+		if item.Status == http.StatusNotModified && body != "" {
+			t.Errorf("[%d] expected empty body for 304, got %s", idx, body)
+		}
+		if item.Status == http.StatusNotFound && resp.Header.Get("ETag") != "" {
+			t.Errorf("[%d] expected no ETag on error, got %q", idx, resp.Header.Get("ETag"))
+		}
+	}
+	// без "memory" метод вызывается на каждый запрос, в том числе на 304
+	if api.Calls["Cached"] != 7 {
+		t.Errorf("expected Cached to be called 7 times, got %d", api.Calls["Cached"])
+	}
+}
+
+func TestCacheMemory(t *testing.T) {
+	api := &CacheApi{}
+	runCases(t, api, []Case{
+		{Path: "/cached/memory?login=ann", Status: http.StatusOK,
+			Result:        `{"error":"","response":{"Login":"ann","Call":1}}`,
+			ResultHeaders: map[string]string{"Cache-Control": "max-age=60"}},
+		{Path: "/cached/memory?login=ann", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Login":"ann","Call":1}}`},
+		// ключ - проверенные параметры: default и лишние параметры дают ту же запись
+		{Path: "/cached/memory", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Login":"ann","Call":1}}`},
+		{Path: "/cached/memory?login=ann&unknown=1", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Login":"ann","Call":1}}`},
+		{Path: "/cached/memory?login=bob", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Login":"bob","Call":2}}`},
+		// ответы на POST не кешируются и не берутся из кеша
+		{Method: http.MethodPost, Path: "/cached/memory", Body: "login=ann", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Login":"ann","Call":3}}`},
+		// ошибки не кешируются
+		{Path: "/cached/memory?login=nobody", Status: http.StatusNotFound,
+			Result: `{"error":"user not exist"}`},
+		{Path: "/cached/memory?login=nobody", Status: http.StatusNotFound,
+			Result: `{"error":"user not exist"}`},
+	})
+	if api.Calls["Memory"] != 5 {
+		t.Errorf("expected Memory to be called 5 times, got %d", api.Calls["Memory"])
+	}
+}
+
+func TestCachePerPrincipal(t *testing.T) {
+	api := &CacheApi{Auth: userAuthenticator{}}
+	user := func(id string) map[string]string {
+		return map[string]string{"X-User": id}
+	}
+	private := map[string]string{"Cache-Control": "private, max-age=60"}
+	runCases(t, api, []Case{
+		{Path: "/cached/private", Headers: user("ann"), Status: http.StatusOK,
+			Result: `{"error":"","response":{"Login":"ann","Call":1}}`, ResultHeaders: private},
+		// у другого автора своя запись
+		{Path: "/cached/private", Headers: user("bob"), Status: http.StatusOK,
+			Result: `{"error":"","response":{"Login":"bob","Call":2}}`, ResultHeaders: private},
+		{Path: "/cached/private", Headers: user("ann"), Status: http.StatusOK,
+			Result: `{"error":"","response":{"Login":"ann","Call":1}}`, ResultHeaders: private},
+		{Path: "/cached/private", Headers: user("bob"), Status: http.StatusOK,
+			Result: `{"error":"","response":{"Login":"bob","Call":2}}`, ResultHeaders: private},
+		// без авторизации кеш не читается
+		{Path: "/cached/private", Status: http.StatusForbidden,
+			Result: `{"error":"no user"}`},
+	})
+	if api.Calls["Private"] != 2 {
+		t.Errorf("expected Private to be called 2 times, got %d", api.Calls["Private"])
+	}
+}
+
+func TestCacheSameURL(t *testing.T) {
+	defaultCache := apigen.DefaultCache
+	apigen.DefaultCache = &apigen.MemoryCache{}
+	defer func() { apigen.DefaultCache = defaultCache }()
+
+	runCases(t, &SameURLCacheApi{}, []Case{
+		{Path: "/cached/same", Status: http.StatusOK, Result: `{"error":"","response":{"Login":"ann","Call":1}}`},
+	})
+	// ответ другого обработчика с тем же url и параметрами не берётся из кеша
+	runCases(t, &OtherSameURLCacheApi{}, []Case{
+		{Path: "/cached/same", Status: http.StatusOK, Result: `{"error":"","response":{"Login":"ann","Call":2}}`},
+		{Path: "/cached/same", Status: http.StatusOK, Result: `{"error":"","response":{"Login":"ann","Call":2}}`},
+	})
+	runCases(t, &SameURLCacheApi{}, []Case{
+		{Path: "/cached/same", Status: http.StatusOK, Result: `{"error":"","response":{"Login":"ann","Call":1}}`},
+	})
+}
+
+// ID автора запроса из заголовка X-User
+type userAuthenticator struct{}
+
+func (userAuthenticator) Authenticate(r *http.Request) (*apigen.Principal, error) {
+	id := r.Header.Get("X-User")
+	if id == "" {
+		return nil, errors.New("no user")
+	}
+	return &apigen.Principal{ID: id}, nil
+}
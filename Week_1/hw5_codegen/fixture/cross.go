@@ -0,0 +1,38 @@
+package fixture
+
+import (
+	"context"
+	"time"
+)
+
+// Правила, сравнивающие поля структуры параметров
+type CrossApi struct{}
+
+type CrossParams struct {
+	Min    int        `apivalidator:"default=0"`
+	Low    int        `apivalidator:"gtefield=Min,default=0"`
+	High   int        `apivalidator:"gtfield=Low,ltefield=Max,default=10"`
+	Max    int        `apivalidator:"max=100,default=100"`
+	Below  *int       `apivalidator:"ltfield=High"`
+	Status string     `apivalidator:"enum=user|admin,default=user"`
+	Login  string     `apivalidator:"required_if=Status:admin,excluded_with=Nick"`
+	Nick   string     `apivalidator:"paramname=nick"`
+	From   time.Time  `apivalidator:"default=2020-01-01T00:00:00Z"`
+	To     *time.Time `apivalidator:"gtfield=From"`
+}
+
+// apigen:api {"url": "/cross"}
+func (api *CrossApi) Check(ctx context.Context, in CrossParams) (*CrossParams, error) {
+	return &in, nil
+}
+
+// Значение required_if с ведущим нулём - десятичное число
+type LevelParams struct {
+	Level int    `apivalidator:"default=0"`
+	Badge string `apivalidator:"required_if=Level:08"`
+}
+
+// apigen:api {"url": "/cross/level"}
+func (api *CrossApi) Level(ctx context.Context, in LevelParams) (*LevelParams, error) {
+	return &in, nil
+}
@@ -0,0 +1,71 @@
+package fixture
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	apigen "github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/apigen/runtime"
+)
+
+func TestCrossFieldRules(t *testing.T) {
+	runCases(t, &CrossApi{}, []Case{
+		{Path: "/cross?min=1&low=1&high=2&max=2&below=1&status=admin&login=ann&to=2020-01-02T00:00:00Z", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Min":1,"Low":1,"High":2,"Max":2,"Below":1,"Status":"admin","Login":"ann",` +
+				`"Nick":"","From":"2020-01-01T00:00:00Z","To":"2020-01-02T00:00:00Z"}}`},
+
+		// gtefield: равенство проходит, меньше - нет
+		{Path: "/cross?min=5&low=4", Status: http.StatusBadRequest,
+			Result: `{"error":"low must be >= min"}`},
+		// gtfield: равенство не проходит
+		{Path: "/cross?low=10&high=10", Status: http.StatusBadRequest,
+			Result: `{"error":"high must be > low"}`},
+		// ltefield
+		{Path: "/cross?high=11&max=10", Status: http.StatusBadRequest,
+			Result: `{"error":"high must be <= max"}`},
+		// ltfield для *int, nil проходит
+		{Path: "/cross?below=10", Status: http.StatusBadRequest,
+			Result: `{"error":"below must be < high"}`},
+		// time.Time сравнивается по времени
+		{Path: "/cross?to=2020-01-01T03:00:00%2B03:00", Status: http.StatusBadRequest,
+			Result: `{"error":"to must be > from"}`},
+
+		// required_if учитывает default у Status
+		{Path: "/cross?status=admin", Status: http.StatusBadRequest,
+			Result: `{"error":"login is required when status is admin"}`},
+		{Path: "/cross?nick=bob", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Min":0,"Low":0,"High":10,"Max":100,"Below":null,"Status":"user","Login":"",` +
+				`"Nick":"bob","From":"2020-01-01T00:00:00Z","To":null}}`},
+		{Path: "/cross?login=ann&nick=bob", Status: http.StatusBadRequest,
+			Result: `{"error":"login must be empty when nick is set"}`},
+
+		// required_if=Level:08 сравнивает с 8
+		{Path: "/cross/level?level=8", Status: http.StatusBadRequest,
+			Result: `{"error":"badge is required when level is 08"}`},
+		{Path: "/cross/level?level=08", Status: http.StatusBadRequest,
+			Result: `{"error":"badge is required when level is 08"}`},
+		{Path: "/cross/level?level=8&badge=gold", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Level":8,"Badge":"gold"}}`},
+		{Path: "/cross/level?level=0", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Level":0,"Badge":""}}`},
+	})
+}
+
+// Правило пропускается, если у одного из полей уже есть ошибка
+func TestCrossFieldRulesSkipFailedFields(t *testing.T) {
+	params := url.Values{"low": {"x"}, "high": {"5"}, "max": {"101"}, "below": {"7"}, "status": {"root"}}
+	_, err := validateAndBuildCrossParams(context.Background(), &CrossApi{}, params, true)
+
+	// high > low и high <= max не проверяются, below < high - проверяется
+	expected := apigen.ValidationErrors{
+		{Param: "low", Rule: "type", Message: "low must be int"},
+		{Param: "max", Rule: "max", Message: "max must be <= 100"},
+		{Param: "status", Rule: "enum", Message: "status must be one of [user, admin]"},
+		{Param: "below", Rule: "ltfield", Message: "below must be < high"},
+	}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("expected %#v, got %#v", expected, err)
+	}
+}
@@ -0,0 +1,13 @@
+// Package dto - структуры параметров, объявленные вне пакета обработчиков
+package dto
+
+type FindParams struct {
+	Name  string `apivalidator:"required"`
+	Limit int    `apivalidator:"min=1,max=10,default=5"`
+}
+
+// Встраивается в структуры параметров других пакетов
+type Pagination struct {
+	Page    int `apivalidator:"min=1,default=1"`
+	PerPage int `apivalidator:"paramname=per_page,max=50,default=10"`
+}
@@ -0,0 +1,14 @@
+// Package fixture - обработчики для тестов кодогенератора. handlers.go собран из директории пакета:
+//
+//	go build handlers_gen/* && ./codegen fixture fixture/handlers.go
+package fixture
+
+// Как ApiError из api.go: сгенерированные обёртки берут статус ошибки из HTTPStatus
+type ApiError struct {
+	HTTPStatus int
+	Err        error
+}
+
+func (ae ApiError) Error() string {
+	return ae.Err.Error()
+}
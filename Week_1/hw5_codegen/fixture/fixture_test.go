@@ -0,0 +1,71 @@
+package fixture
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Case struct {
+	Method  string
+	Path    string
+	Body    string
+	Headers map[string]string
+	Status  int
+	// Ожидаемое тело ответа, JSON сравнивается по значению
+	Result string
+	// Ожидаемые заголовки ответа, остальные не проверяются
+	ResultHeaders map[string]string
+}
+
+func runCases(t *testing.T, handler http.Handler, cases []Case) {
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	for idx, item := range cases {
+		method := item.Method
+		if method == "" {
+			method = http.MethodGet
+		}
+		req, _ := http.NewRequest(method, ts.URL+item.Path, strings.NewReader(item.Body))
+		if item.Body != "" {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		for key, value := range item.Headers {
+			req.Header.Set(key, value)
+		}
+
+		resp, err := ts.Client().Do(req)
+		if err != nil {
+			t.Errorf("[%d] request error: %v", idx, err)
+			continue
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != item.Status {
+			t.Errorf("[%d] %s %s: expected http status %v, got %v", idx, method, item.Path, item.Status, resp.StatusCode)
+		}
+		if !sameBody(body, item.Result) {
+			t.Errorf("[%d] %s %s: expected body %s, got %s", idx, method, item.Path, item.Result, body)
+		}
+		for key, value := range item.ResultHeaders {
+			if got := resp.Header.Get(key); got != value {
+				t.Errorf("[%d] %s %s: expected header %s %q, got %q", idx, method, item.Path, key, value, got)
+			}
+		}
+	}
+}
+
+// json.Marshal экранирует <, > и &, поэтому JSON сравниваем после разбора
+func sameBody(body []byte, expected string) bool {
+	var got, want interface{}
+	if json.Unmarshal(body, &got) == nil && json.Unmarshal([]byte(expected), &want) == nil {
+		return reflect.DeepEqual(got, want)
+	}
+	return string(body) == expected
+}
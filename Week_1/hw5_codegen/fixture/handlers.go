@@ -0,0 +1,4992 @@
+package fixture
+
+import (
+	"context"
+	"errors"
+	apigen "github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/apigen/runtime"
+	"github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/fixture/dto"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// auto-generated file: do not edit!
+
+// Не скомпилируется с версией apigen/runtime, в которой нет API этого файла
+const _ = apigen.APIVersion8
+
+func (h *CacheApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/cached":
+		h.wrapperCached(w, r, nil)
+	case "/cached/memory":
+		h.wrapperMemory(w, r, nil)
+	case "/cached/private":
+		h.wrapperPrivate(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *CacheApi) getAuthenticator() apigen.Authenticator {
+	if h.Auth != nil {
+		return h.Auth
+	}
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *CacheApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
+}
+
+func (h *CacheApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *CacheApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *CacheApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *CacheApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *CacheApi) wrapperCached(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/cached", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "CacheApi.Cached", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleCached(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *CacheApi) handleCached(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/cached")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildCacheParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/cached")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Cached(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "CacheApi.Cached", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	// cache: 30s
+	apigen.WriteWithETag(w, r, encoder, res, time.Duration(30000000000), false)
+}
+
+func (h *CacheApi) wrapperMemory(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/cached/memory", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "CacheApi.Memory", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleMemory(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *CacheApi) handleMemory(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/cached/memory")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildCacheParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/cached/memory")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	cacheKey, cacheable := apigen.CacheKey("CacheApi.Memory /cached/memory", r, p0)
+	if cacheable {
+		if cached, ok := h.getCache().Get(cacheKey); ok {
+			apigen.WriteWithETag(w, r, encoder, cached, time.Duration(60000000000), false)
+			return
+		}
+	}
+
+	res, err := h.Memory(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "CacheApi.Memory", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	if cacheable {
+		h.getCache().Set(cacheKey, res, time.Duration(60000000000))
+	}
+	// cache: 1m
+	apigen.WriteWithETag(w, r, encoder, res, time.Duration(60000000000), false)
+}
+
+func (h *CacheApi) wrapperPrivate(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/cached/private", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "CacheApi.Private", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	principal, err := h.getAuthenticator().Authenticate(r)
+	if err != nil {
+		status := http.StatusForbidden
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	ctx = apigen.ContextWithPrincipal(ctx, principal)
+
+	r = r.WithContext(ctx)
+	h.handlePrivate(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *CacheApi) handlePrivate(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/cached/private")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildCacheParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/cached/private")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	cacheKey, cacheable := apigen.CacheKey("CacheApi.Private /cached/private", r, p0)
+	if cacheable {
+		if cached, ok := h.getCache().Get(cacheKey); ok {
+			apigen.WriteWithETag(w, r, encoder, cached, time.Duration(60000000000), true)
+			return
+		}
+	}
+
+	res, err := h.Private(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "CacheApi.Private", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	if cacheable {
+		h.getCache().Set(cacheKey, res, time.Duration(60000000000))
+	}
+	// cache: 1m
+	apigen.WriteWithETag(w, r, encoder, res, time.Duration(60000000000), true)
+}
+
+func (h *CrossApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/cross":
+		h.wrapperCheck(w, r, nil)
+	case "/cross/level":
+		h.wrapperLevel(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *CrossApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *CrossApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
+}
+
+func (h *CrossApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *CrossApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *CrossApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *CrossApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *CrossApi) wrapperCheck(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/cross", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "CrossApi.Check", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleCheck(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *CrossApi) handleCheck(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/cross")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildCrossParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/cross")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Check(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "CrossApi.Check", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *CrossApi) wrapperLevel(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/cross/level", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "CrossApi.Level", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleLevel(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *CrossApi) handleLevel(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/cross/level")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildLevelParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/cross/level")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Level(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "CrossApi.Level", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *FieldAuthApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/moderate":
+		h.wrapperModerate(w, r, nil)
+	case "/who":
+		h.wrapperWho(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *FieldAuthApi) getAuthenticator() apigen.Authenticator {
+	if h.Auth != nil {
+		return h.Auth
+	}
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *FieldAuthApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
+}
+
+func (h *FieldAuthApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *FieldAuthApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *FieldAuthApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *FieldAuthApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *FieldAuthApi) wrapperModerate(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/moderate", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "FieldAuthApi.Moderate", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	principal, err := h.getAuthenticator().Authenticate(r)
+	if err != nil {
+		status := http.StatusForbidden
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	ctx = apigen.ContextWithPrincipal(ctx, principal)
+
+	if !principal.HasAnyRole("admin", "moderator") {
+		encoder.EncodeError(w, r, http.StatusForbidden, errors.New("forbidden"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleModerate(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *FieldAuthApi) handleModerate(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/moderate")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildWhoParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/moderate")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Moderate(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "FieldAuthApi.Moderate", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *FieldAuthApi) wrapperWho(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/who", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "FieldAuthApi.Who", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	principal, err := h.getAuthenticator().Authenticate(r)
+	if err != nil {
+		status := http.StatusForbidden
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	ctx = apigen.ContextWithPrincipal(ctx, principal)
+
+	r = r.WithContext(ctx)
+	h.handleWho(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *FieldAuthApi) handleWho(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/who")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildWhoParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/who")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Who(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "FieldAuthApi.Who", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *HooksApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/hook":
+		h.wrapperHook(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *HooksApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *HooksApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
+}
+
+func (h *HooksApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *HooksApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *HooksApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *HooksApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *HooksApi) wrapperHook(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/hook", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "HooksApi.Hook", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleHook(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *HooksApi) handleHook(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/hook")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildHookParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/hook")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Hook(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "HooksApi.Hook", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *MiddlewareApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/chain":
+		h.wrapperChain(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *MiddlewareApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *MiddlewareApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
+}
+
+func (h *MiddlewareApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *MiddlewareApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *MiddlewareApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *MiddlewareApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *MiddlewareApi) wrapperChain(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/chain", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "MiddlewareApi.Chain", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	principal, err := h.getAuthenticator().Authenticate(r)
+	if err != nil {
+		status := http.StatusForbidden
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	ctx = apigen.ContextWithPrincipal(ctx, principal)
+
+	r = r.WithContext(ctx)
+
+	var next http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.handleChain(w, r, encoder, pathParams)
+	})
+	next = inner(next)
+	next = outer(next)
+	next.ServeHTTP(w, r)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *MiddlewareApi) handleChain(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/chain")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildChainParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/chain")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Chain(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "MiddlewareApi.Chain", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *NestedApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/nested":
+		h.wrapperList(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *NestedApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *NestedApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
+}
+
+func (h *NestedApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *NestedApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *NestedApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *NestedApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *NestedApi) wrapperList(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/nested", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "NestedApi.List", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleList(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *NestedApi) handleList(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/nested")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildNestedParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/nested")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.List(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "NestedApi.List", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *OtherSameURLCacheApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/cached/same":
+		h.wrapperSame(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *OtherSameURLCacheApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *OtherSameURLCacheApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
+}
+
+func (h *OtherSameURLCacheApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *OtherSameURLCacheApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *OtherSameURLCacheApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *OtherSameURLCacheApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *OtherSameURLCacheApi) wrapperSame(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/cached/same", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "OtherSameURLCacheApi.Same", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleSame(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *OtherSameURLCacheApi) handleSame(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/cached/same")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildCacheParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/cached/same")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	cacheKey, cacheable := apigen.CacheKey("OtherSameURLCacheApi.Same /cached/same", r, p0)
+	if cacheable {
+		if cached, ok := h.getCache().Get(cacheKey); ok {
+			apigen.WriteWithETag(w, r, encoder, cached, time.Duration(60000000000), false)
+			return
+		}
+	}
+
+	res, err := h.Same(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "OtherSameURLCacheApi.Same", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	if cacheable {
+		h.getCache().Set(cacheKey, res, time.Duration(60000000000))
+	}
+	// cache: 1m
+	apigen.WriteWithETag(w, r, encoder, res, time.Duration(60000000000), false)
+}
+
+func (h *OtherSameURLLimitApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/limited/same":
+		h.wrapperPing(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *OtherSameURLLimitApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *OtherSameURLLimitApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
+}
+
+func (h *OtherSameURLLimitApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *OtherSameURLLimitApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *OtherSameURLLimitApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *OtherSameURLLimitApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *OtherSameURLLimitApi) wrapperPing(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/limited/same", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "OtherSameURLLimitApi.Ping", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	// ratelimit: 1/m per ip
+	if !apigen.AllowRequest(h.getLimiter(), w, apigen.RateLimitKey("OtherSameURLLimitApi.Ping /limited/same", r, nil), apigen.Rate{Limit: 1, Per: time.Duration(60000000000)}) {
+		encoder.EncodeError(w, r, http.StatusTooManyRequests, errors.New("too many requests"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handlePing(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *OtherSameURLLimitApi) handlePing(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/limited/same")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildWhoParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/limited/same")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Ping(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "OtherSameURLLimitApi.Ping", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *ProblemRequestIDApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/find":
+		h.wrapperFind(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *ProblemRequestIDApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *ProblemRequestIDApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.ProblemEncoder{RequestID: true}
+}
+
+func (h *ProblemRequestIDApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *ProblemRequestIDApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *ProblemRequestIDApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *ProblemRequestIDApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *ProblemRequestIDApi) wrapperFind(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/find", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "ProblemRequestIDApi.Find", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleFind(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *ProblemRequestIDApi) handleFind(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/find")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildFindParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/find")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Find(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "ProblemRequestIDApi.Find", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *RateLimitApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/limited/auth":
+		h.wrapperPerAuth(w, r, nil)
+	case "/limited/ip":
+		h.wrapperPerIP(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *RateLimitApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *RateLimitApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
+}
+
+func (h *RateLimitApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *RateLimitApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *RateLimitApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *RateLimitApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *RateLimitApi) wrapperPerAuth(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/limited/auth", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "RateLimitApi.PerAuth", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	principal, err := h.getAuthenticator().Authenticate(r)
+	if err != nil {
+		status := http.StatusForbidden
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	ctx = apigen.ContextWithPrincipal(ctx, principal)
+
+	if !principal.HasAnyRole("admin") {
+		encoder.EncodeError(w, r, http.StatusForbidden, errors.New("forbidden"))
+		return
+	}
+
+	// ratelimit: 2/m per auth
+	if !apigen.AllowRequest(h.getLimiter(), w, apigen.RateLimitKey("RateLimitApi.PerAuth /limited/auth", r, principal), apigen.Rate{Limit: 2, Per: time.Duration(60000000000)}) {
+		encoder.EncodeError(w, r, http.StatusTooManyRequests, errors.New("too many requests"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handlePerAuth(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *RateLimitApi) handlePerAuth(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/limited/auth")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildWhoParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/limited/auth")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.PerAuth(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "RateLimitApi.PerAuth", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *RateLimitApi) wrapperPerIP(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/limited/ip", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "RateLimitApi.PerIP", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	// ratelimit: 2/m per ip
+	if !apigen.AllowRequest(h.getLimiter(), w, apigen.RateLimitKey("RateLimitApi.PerIP /limited/ip", r, nil), apigen.Rate{Limit: 2, Per: time.Duration(60000000000)}) {
+		encoder.EncodeError(w, r, http.StatusTooManyRequests, errors.New("too many requests"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handlePerIP(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *RateLimitApi) handlePerIP(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/limited/ip")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildWhoParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/limited/ip")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.PerIP(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "RateLimitApi.PerIP", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *RequestIDApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/find":
+		h.wrapperFind(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *RequestIDApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *RequestIDApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.EnvelopeEncoder{RequestID: true}
+}
+
+func (h *RequestIDApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *RequestIDApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *RequestIDApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *RequestIDApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *RequestIDApi) wrapperFind(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/find", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "RequestIDApi.Find", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleFind(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *RequestIDApi) handleFind(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/find")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildFindParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/find")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Find(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "RequestIDApi.Find", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *RoutesApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/users/me":
+		h.wrapperMe(w, r, nil)
+	default:
+		if m := routeRoutesApiPage.FindStringSubmatch(r.URL.Path); m != nil {
+			h.wrapperPage(w, r, url.Values{
+				"n": {m[1]},
+			})
+			return
+		}
+		if m := routeRoutesApiArticle.FindStringSubmatch(r.URL.Path); m != nil {
+			h.wrapperArticle(w, r, url.Values{
+				"slug": {m[1]},
+			})
+			return
+		}
+		if m := routeRoutesApiBlock.FindStringSubmatch(r.URL.Path); m != nil {
+			h.wrapperBlock(w, r, url.Values{
+				"n": {m[1]},
+			})
+			return
+		}
+		if m := routeRoutesApiItem.FindStringSubmatch(r.URL.Path); m != nil {
+			h.wrapperItem(w, r, url.Values{
+				"id": {m[1]},
+			})
+			return
+		}
+		if m := routeRoutesApiProfile.FindStringSubmatch(r.URL.Path); m != nil {
+			h.wrapperProfile(w, r, url.Values{
+				"login": {m[1]},
+			})
+			return
+		}
+		if m := routeRoutesApiUser.FindStringSubmatch(r.URL.Path); m != nil {
+			h.wrapperUser(w, r, url.Values{
+				"login": {m[1]},
+			})
+			return
+		}
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+var routeRoutesApiArticle = regexp.MustCompile("^/pages/([^/]+)$")
+
+var routeRoutesApiBlock = regexp.MustCompile("^/blocks/([0-9]+)$")
+
+var routeRoutesApiItem = regexp.MustCompile("^/items/(-?[0-9]+)$")
+
+var routeRoutesApiPage = regexp.MustCompile("^/pages/([0-9]+)$")
+
+var routeRoutesApiProfile = regexp.MustCompile("^/users/([^/]+)/profile$")
+
+var routeRoutesApiUser = regexp.MustCompile("^/users/([^/]+)$")
+
+func (h *RoutesApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *RoutesApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
+}
+
+func (h *RoutesApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *RoutesApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *RoutesApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *RoutesApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *RoutesApi) wrapperArticle(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/pages/{slug}", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "RoutesApi.Article", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleArticle(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *RoutesApi) handleArticle(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/pages/{slug}")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildSlugParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/pages/{slug}")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Article(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "RoutesApi.Article", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *RoutesApi) wrapperBlock(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/blocks/{n:uint}", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "RoutesApi.Block", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleBlock(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *RoutesApi) handleBlock(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/blocks/{n:uint}")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildBlockParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/blocks/{n:uint}")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Block(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "RoutesApi.Block", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *RoutesApi) wrapperItem(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/items/{id:int}", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "RoutesApi.Item", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleItem(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *RoutesApi) handleItem(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/items/{id:int}")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildItemParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/items/{id:int}")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Item(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "RoutesApi.Item", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *RoutesApi) wrapperMe(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/users/me", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "RoutesApi.Me", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleMe(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *RoutesApi) handleMe(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/users/me")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildWhoParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/users/me")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Me(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "RoutesApi.Me", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *RoutesApi) wrapperPage(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/pages/{n:uint}", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "RoutesApi.Page", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handlePage(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *RoutesApi) handlePage(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/pages/{n:uint}")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildBlockParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/pages/{n:uint}")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Page(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "RoutesApi.Page", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *RoutesApi) wrapperProfile(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/users/{login}/profile", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "RoutesApi.Profile", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleProfile(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *RoutesApi) handleProfile(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/users/{login}/profile")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildUserParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/users/{login}/profile")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Profile(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "RoutesApi.Profile", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *RoutesApi) wrapperUser(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/users/{login}", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "RoutesApi.User", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleUser(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *RoutesApi) handleUser(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/users/{login}")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildUserParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/users/{login}")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.User(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "RoutesApi.User", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *SameURLCacheApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/cached/same":
+		h.wrapperSame(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *SameURLCacheApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *SameURLCacheApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
+}
+
+func (h *SameURLCacheApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *SameURLCacheApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *SameURLCacheApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *SameURLCacheApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *SameURLCacheApi) wrapperSame(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/cached/same", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "SameURLCacheApi.Same", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleSame(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *SameURLCacheApi) handleSame(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/cached/same")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildCacheParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/cached/same")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	cacheKey, cacheable := apigen.CacheKey("SameURLCacheApi.Same /cached/same", r, p0)
+	if cacheable {
+		if cached, ok := h.getCache().Get(cacheKey); ok {
+			apigen.WriteWithETag(w, r, encoder, cached, time.Duration(60000000000), false)
+			return
+		}
+	}
+
+	res, err := h.Same(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "SameURLCacheApi.Same", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	if cacheable {
+		h.getCache().Set(cacheKey, res, time.Duration(60000000000))
+	}
+	// cache: 1m
+	apigen.WriteWithETag(w, r, encoder, res, time.Duration(60000000000), false)
+}
+
+func (h *SameURLLimitApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/limited/same":
+		h.wrapperPing(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *SameURLLimitApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *SameURLLimitApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
+}
+
+func (h *SameURLLimitApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *SameURLLimitApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *SameURLLimitApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *SameURLLimitApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *SameURLLimitApi) wrapperPing(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/limited/same", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "SameURLLimitApi.Ping", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	// ratelimit: 1/m per ip
+	if !apigen.AllowRequest(h.getLimiter(), w, apigen.RateLimitKey("SameURLLimitApi.Ping /limited/same", r, nil), apigen.Rate{Limit: 1, Per: time.Duration(60000000000)}) {
+		encoder.EncodeError(w, r, http.StatusTooManyRequests, errors.New("too many requests"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handlePing(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *SameURLLimitApi) handlePing(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/limited/same")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildWhoParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/limited/same")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Ping(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "SameURLLimitApi.Ping", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *SearchApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/search":
+		h.wrapperFind(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *SearchApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *SearchApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
+}
+
+func (h *SearchApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *SearchApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *SearchApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *SearchApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *SearchApi) wrapperFind(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/search", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "SearchApi.Find", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleFind(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *SearchApi) handleFind(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/search")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildDtoFindParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/search")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Find(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "SearchApi.Find", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *SelfAuthApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/who":
+		h.wrapperWho(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *SelfAuthApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *SelfAuthApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
+}
+
+func (h *SelfAuthApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *SelfAuthApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *SelfAuthApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *SelfAuthApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *SelfAuthApi) wrapperWho(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/who", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "SelfAuthApi.Who", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	principal, err := h.getAuthenticator().Authenticate(r)
+	if err != nil {
+		status := http.StatusForbidden
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	ctx = apigen.ContextWithPrincipal(ctx, principal)
+
+	r = r.WithContext(ctx)
+	h.handleWho(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *SelfAuthApi) handleWho(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/who")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildWhoParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/who")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Who(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "SelfAuthApi.Who", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *StringsApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/strings":
+		h.wrapperCheck(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *StringsApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *StringsApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
+}
+
+func (h *StringsApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *StringsApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *StringsApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *StringsApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *StringsApi) wrapperCheck(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/strings", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "StringsApi.Check", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleCheck(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *StringsApi) handleCheck(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/strings")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildStringsParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/strings")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Check(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "StringsApi.Check", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *TimeoutApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/sleep":
+		h.wrapperSleep(w, r, nil)
+	case "/sleep/wrapped":
+		h.wrapperSleepWrapped(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *TimeoutApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *TimeoutApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
+}
+
+func (h *TimeoutApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *TimeoutApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *TimeoutApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *TimeoutApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *TimeoutApi) wrapperSleep(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/sleep", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "TimeoutApi.Sleep", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	// timeout: 50ms
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(50000000))
+	defer cancel()
+	r = r.WithContext(ctx)
+	h.handleSleep(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *TimeoutApi) handleSleep(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/sleep")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildSleepParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/sleep")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Sleep(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			if errors.Is(err, context.DeadlineExceeded) {
+				status = http.StatusGatewayTimeout
+			}
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "TimeoutApi.Sleep", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *TimeoutApi) wrapperSleepWrapped(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/sleep/wrapped", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "TimeoutApi.SleepWrapped", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	// timeout: 50ms
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(50000000))
+	defer cancel()
+	r = r.WithContext(ctx)
+	h.handleSleepWrapped(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *TimeoutApi) handleSleepWrapped(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/sleep/wrapped")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildSleepParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/sleep/wrapped")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.SleepWrapped(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			if errors.Is(err, context.DeadlineExceeded) {
+				status = http.StatusGatewayTimeout
+			}
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "TimeoutApi.SleepWrapped", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *TypesApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/types":
+		h.wrapperEcho(w, r, nil)
+	case "/score":
+		h.wrapperScore(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *TypesApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *TypesApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
+}
+
+func (h *TypesApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *TypesApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *TypesApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *TypesApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *TypesApi) wrapperEcho(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/types", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "TypesApi.Echo", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleEcho(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *TypesApi) handleEcho(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/types")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildTypesParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/types")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Echo(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "TypesApi.Echo", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *TypesApi) wrapperScore(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/score", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "TypesApi.Score", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleScore(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *TypesApi) handleScore(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/score")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildScoreParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/score")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Score(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "TypesApi.Score", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildBlockParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*BlockParams, error) {
+	res := BlockParams{}
+
+	var errs apigen.ValidationErrors
+	var err error
+
+	err = func() error {
+		paramValue := params.Get("n")
+
+		HeightVal, err := strconv.ParseUint(paramValue, 10, 64)
+		if err != nil {
+			return apigen.FieldError{Param: "n", Rule: "type", Message: "n must be uint64"}
+		}
+
+		res.Height = HeightVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildCacheParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*CacheParams, error) {
+	res := CacheParams{}
+
+	var errs apigen.ValidationErrors
+	var err error
+
+	err = func() error {
+		paramValue := params.Get("login")
+		if paramValue == "" {
+			paramValue = "ann"
+		}
+
+		LoginVal := paramValue
+
+		res.Login = LoginVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildChainParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*ChainParams, error) {
+	res := ChainParams{}
+
+	var errs apigen.ValidationErrors
+	var err error
+
+	err = func() error {
+		paramValue := params.Get("name")
+		if paramValue == "" {
+			return apigen.FieldError{Param: "name", Rule: "required", Message: "name must me not empty"}
+		}
+
+		NameVal := paramValue
+
+		res.Name = NameVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
+
+const maxCrossParamsMax int = 100
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildCrossParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*CrossParams, error) {
+	res := CrossParams{}
+
+	var errs apigen.ValidationErrors
+	var err error
+
+	err = func() error {
+		paramValue := params.Get("min")
+		if paramValue == "" {
+			paramValue = "0"
+		}
+
+		MinVal, err := strconv.Atoi(paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: "min", Rule: "type", Message: "min must be int"}
+		}
+
+		res.Min = MinVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("low")
+		if paramValue == "" {
+			paramValue = "0"
+		}
+
+		LowVal, err := strconv.Atoi(paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: "low", Rule: "type", Message: "low must be int"}
+		}
+
+		res.Low = LowVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("high")
+		if paramValue == "" {
+			paramValue = "10"
+		}
+
+		HighVal, err := strconv.Atoi(paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: "high", Rule: "type", Message: "high must be int"}
+		}
+
+		res.High = HighVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("max")
+		if paramValue == "" {
+			paramValue = "100"
+		}
+
+		MaxVal, err := strconv.Atoi(paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: "max", Rule: "type", Message: "max must be int"}
+		}
+		if MaxVal > maxCrossParamsMax {
+			return apigen.FieldError{Param: "max", Rule: "max", Message: "max must be <= 100"}
+		}
+
+		res.Max = MaxVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("below")
+
+		if paramValue != "" {
+
+			BelowVal, err := strconv.Atoi(paramValue)
+			if err != nil {
+				return apigen.FieldError{Param: "below", Rule: "type", Message: "below must be int"}
+			}
+
+			res.Below = &BelowVal
+		}
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("status")
+		if paramValue == "" {
+			paramValue = "user"
+		}
+
+		StatusVal := paramValue
+
+		switch StatusVal {
+		case "user", "admin":
+		default:
+			return apigen.FieldError{Param: "status", Rule: "enum", Message: "status must be one of [user, admin]"}
+		}
+
+		res.Status = StatusVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("login")
+
+		LoginVal := paramValue
+
+		res.Login = LoginVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("nick")
+
+		NickVal := paramValue
+
+		res.Nick = NickVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("from")
+		if paramValue == "" {
+			paramValue = "2020-01-01T00:00:00Z"
+		}
+
+		FromVal, err := time.Parse(time.RFC3339, paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: "from", Rule: "type", Message: "from must be RFC3339 time"}
+		}
+
+		res.From = FromVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("to")
+
+		if paramValue != "" {
+
+			ToVal, err := time.Parse(time.RFC3339, paramValue)
+			if err != nil {
+				return apigen.FieldError{Param: "to", Rule: "type", Message: "to must be RFC3339 time"}
+			}
+
+			res.To = &ToVal
+		}
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if !errs.Has("low") && !errs.Has("min") && res.Low < res.Min {
+		err = apigen.FieldError{Param: "low", Rule: "gtefield", Message: "low must be >= min"}
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if !errs.Has("high") && !errs.Has("low") && !(res.Low < res.High) {
+		err = apigen.FieldError{Param: "high", Rule: "gtfield", Message: "high must be > low"}
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if !errs.Has("high") && !errs.Has("max") && res.Max < res.High {
+		err = apigen.FieldError{Param: "high", Rule: "ltefield", Message: "high must be <= max"}
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if !errs.Has("below") && !errs.Has("high") && res.Below != nil && !((*res.Below) < res.High) {
+		err = apigen.FieldError{Param: "below", Rule: "ltfield", Message: "below must be < high"}
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if !errs.Has("login") && !errs.Has("status") && res.Status == "admin" && !(params.Get("login") != "") {
+		err = apigen.FieldError{Param: "login", Rule: "required_if", Message: "login is required when status is admin"}
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if !errs.Has("login") && !errs.Has("nick") && (params.Get("login") != "") && (params.Get("nick") != "") {
+		err = apigen.FieldError{Param: "login", Rule: "excluded_with", Message: "login must be empty when nick is set"}
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if !errs.Has("to") && !errs.Has("from") && res.To != nil && !(res.From.Before((*res.To))) {
+		err = apigen.FieldError{Param: "to", Rule: "gtfield", Message: "to must be > from"}
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildFindParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*FindParams, error) {
+	res := FindParams{}
+
+	var errs apigen.ValidationErrors
+	var err error
+
+	err = func() error {
+		paramValue := params.Get("login")
+		if paramValue == "" {
+			return apigen.FieldError{Param: "login", Rule: "required", Message: "login must me not empty"}
+		}
+
+		LoginVal := paramValue
+
+		res.Login = LoginVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildHookParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*HookParams, error) {
+	res := HookParams{}
+
+	var errs apigen.ValidationErrors
+	var err error
+
+	err = func() error {
+		paramValue := params.Get("login")
+		if paramValue == "" {
+			return apigen.FieldError{Param: "login", Rule: "required", Message: "login must me not empty"}
+		}
+
+		LoginVal := paramValue
+		if err := checkLogin(LoginVal); err != nil {
+			return apigen.FieldError{Param: "login", Rule: "validate", Message: err.Error()}
+		}
+
+		res.Login = LoginVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("year")
+		if paramValue == "" {
+			paramValue = "2000"
+		}
+
+		YearVal, err := strconv.Atoi(paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: "year", Rule: "type", Message: "year must be int"}
+		}
+		if err := h.(interface {
+			checkYear(context.Context, int) error
+		}).checkYear(ctx, YearVal); err != nil {
+			return apigen.FieldError{Param: "year", Rule: "validate", Message: err.Error()}
+		}
+
+		res.Year = YearVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("when")
+
+		if paramValue != "" {
+
+			WhenVal, err := time.Parse(time.RFC3339, paramValue)
+			if err != nil {
+				return apigen.FieldError{Param: "when", Rule: "type", Message: "when must be RFC3339 time"}
+			}
+			if err := checkWhen(ctx, WhenVal); err != nil {
+				return apigen.FieldError{Param: "when", Rule: "validate", Message: err.Error()}
+			}
+
+			res.When = &WhenVal
+		}
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		TagsValues := params["tag"]
+
+		res.Tags = make([]string, 0, len(TagsValues))
+		for _, paramValue := range TagsValues {
+
+			TagsVal := paramValue
+			if err := checkLogin(TagsVal); err != nil {
+				return apigen.FieldError{Param: "tag", Rule: "validate", Message: err.Error()}
+			}
+
+			res.Tags = append(res.Tags, TagsVal)
+		}
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
+
+const minItemParamsID int = -10
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildItemParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*ItemParams, error) {
+	res := ItemParams{}
+
+	var errs apigen.ValidationErrors
+	var err error
+
+	err = func() error {
+		paramValue := params.Get("id")
+
+		IDVal, err := strconv.Atoi(paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: "id", Rule: "type", Message: "id must be int"}
+		}
+		if IDVal < minItemParamsID {
+			return apigen.FieldError{Param: "id", Rule: "min", Message: "id must be >= -10"}
+		}
+
+		res.ID = IDVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildLevelParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*LevelParams, error) {
+	res := LevelParams{}
+
+	var errs apigen.ValidationErrors
+	var err error
+
+	err = func() error {
+		paramValue := params.Get("level")
+		if paramValue == "" {
+			paramValue = "0"
+		}
+
+		LevelVal, err := strconv.Atoi(paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: "level", Rule: "type", Message: "level must be int"}
+		}
+
+		res.Level = LevelVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("badge")
+
+		BadgeVal := paramValue
+
+		res.Badge = BadgeVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if !errs.Has("badge") && !errs.Has("level") && res.Level == 8 && !(params.Get("badge") != "") {
+		err = apigen.FieldError{Param: "badge", Rule: "required_if", Message: "badge is required when level is 08"}
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
+
+const minNestedParamsPaginationPage int = 1
+const maxNestedParamsPaginationPerPage int = 50
+const minNestedParamsFilterAge int = 1
+const minNestedParamsExtraAge int = 1
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildNestedParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*NestedParams, error) {
+	res := NestedParams{}
+	res.Extra = &NestedFilter{}
+
+	var errs apigen.ValidationErrors
+	var err error
+
+	err = func() error {
+		paramValue := params.Get("q")
+		if paramValue == "" {
+			return apigen.FieldError{Param: "q", Rule: "required", Message: "q must me not empty"}
+		}
+
+		QueryVal := paramValue
+
+		res.Query = QueryVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("page")
+		if paramValue == "" {
+			paramValue = "1"
+		}
+
+		PaginationPageVal, err := strconv.Atoi(paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: "page", Rule: "type", Message: "page must be int"}
+		}
+		if PaginationPageVal < minNestedParamsPaginationPage {
+			return apigen.FieldError{Param: "page", Rule: "min", Message: "page must be >= 1"}
+		}
+
+		res.Pagination.Page = PaginationPageVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("per_page")
+		if paramValue == "" {
+			paramValue = "10"
+		}
+
+		PaginationPerPageVal, err := strconv.Atoi(paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: "per_page", Rule: "type", Message: "per_page must be int"}
+		}
+		if PaginationPerPageVal > maxNestedParamsPaginationPerPage {
+			return apigen.FieldError{Param: "per_page", Rule: "max", Message: "per_page must be <= 50"}
+		}
+
+		res.Pagination.PerPage = PaginationPerPageVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("filter.name")
+		if paramValue == "" {
+			return apigen.FieldError{Param: "filter.name", Rule: "required", Message: "filter.name must me not empty"}
+		}
+
+		FilterNameVal := paramValue
+
+		res.Filter.Name = FilterNameVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("filter.age")
+
+		if paramValue != "" {
+
+			FilterAgeVal, err := strconv.Atoi(paramValue)
+			if err != nil {
+				return apigen.FieldError{Param: "filter.age", Rule: "type", Message: "filter.age must be int"}
+			}
+			if FilterAgeVal < minNestedParamsFilterAge {
+				return apigen.FieldError{Param: "filter.age", Rule: "min", Message: "filter.age must be >= 1"}
+			}
+
+			res.Filter.Age = &FilterAgeVal
+		}
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("x.name")
+		if paramValue == "" {
+			return apigen.FieldError{Param: "x.name", Rule: "required", Message: "x.name must me not empty"}
+		}
+
+		ExtraNameVal := paramValue
+
+		res.Extra.Name = ExtraNameVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("x.age")
+
+		if paramValue != "" {
+
+			ExtraAgeVal, err := strconv.Atoi(paramValue)
+			if err != nil {
+				return apigen.FieldError{Param: "x.age", Rule: "type", Message: "x.age must be int"}
+			}
+			if ExtraAgeVal < minNestedParamsExtraAge {
+				return apigen.FieldError{Param: "x.age", Rule: "min", Message: "x.age must be >= 1"}
+			}
+
+			res.Extra.Age = &ExtraAgeVal
+		}
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
+
+const minScoreParamsScore float64 = 0.5
+const maxScoreParamsScore float64 = 9.5
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildScoreParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*ScoreParams, error) {
+	res := ScoreParams{}
+
+	var errs apigen.ValidationErrors
+	var err error
+
+	err = func() error {
+		paramValue := params.Get("score")
+		if paramValue == "" {
+			return apigen.FieldError{Param: "score", Rule: "required", Message: "score must me not empty"}
+		}
+
+		ScoreVal, err := strconv.ParseFloat(paramValue, 64)
+		if err != nil || math.IsNaN(ScoreVal) || math.IsInf(ScoreVal, 0) {
+			return apigen.FieldError{Param: "score", Rule: "type", Message: "score must be float64"}
+		}
+		if !(ScoreVal >= minScoreParamsScore) {
+			return apigen.FieldError{Param: "score", Rule: "min", Message: "score must be >= 0.5"}
+		}
+		if !(ScoreVal <= maxScoreParamsScore) {
+			return apigen.FieldError{Param: "score", Rule: "max", Message: "score must be <= 9.5"}
+		}
+
+		res.Score = ScoreVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildSleepParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*SleepParams, error) {
+	res := SleepParams{}
+
+	var errs apigen.ValidationErrors
+	var err error
+
+	err = func() error {
+		paramValue := params.Get("for")
+		if paramValue == "" {
+			paramValue = "0s"
+		}
+
+		ForVal, err := time.ParseDuration(paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: "for", Rule: "type", Message: "for must be duration"}
+		}
+
+		res.For = ForVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildSlugParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*SlugParams, error) {
+	res := SlugParams{}
+
+	var errs apigen.ValidationErrors
+	var err error
+
+	err = func() error {
+		paramValue := params.Get("slug")
+		if paramValue == "" {
+			return apigen.FieldError{Param: "slug", Rule: "required", Message: "slug must me not empty"}
+		}
+
+		SlugVal := paramValue
+
+		res.Slug = SlugVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
+
+var patternStringsParamsCode = regexp.MustCompile("^[A-Z]{2,3}$")
+var patternStringsParamsZip = regexp.MustCompile("^\\d{5}$")
+var patternStringsParamsTags = regexp.MustCompile("^[a-z]+$")
+
+const minLenStringsParamsNick int = 2
+const maxLenStringsParamsNick int = 5
+const maxLenStringsParamsTags int = 4
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildStringsParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*StringsParams, error) {
+	res := StringsParams{}
+
+	var errs apigen.ValidationErrors
+	var err error
+
+	err = func() error {
+		paramValue := params.Get("code")
+		if paramValue == "" {
+			paramValue = "RU"
+		}
+
+		CodeVal := paramValue
+		if !patternStringsParamsCode.MatchString(CodeVal) {
+			return apigen.FieldError{Param: "code", Rule: "pattern", Message: "code must match ^[A-Z]{2,3}$"}
+		}
+
+		res.Code = CodeVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("zip")
+
+		if paramValue != "" {
+
+			ZipVal := paramValue
+			if !patternStringsParamsZip.MatchString(ZipVal) {
+				return apigen.FieldError{Param: "zip", Rule: "pattern", Message: "zip must match ^\\d{5}$"}
+			}
+
+			res.Zip = &ZipVal
+		}
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("email")
+		if paramValue == "" {
+			paramValue = "ann@example.com"
+		}
+
+		EmailVal := paramValue
+		if err := apigen.ValidateFormatEmail(EmailVal, "email"); err != nil {
+			return err
+		}
+
+		res.Email = EmailVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("id")
+		if paramValue == "" {
+			paramValue = "123e4567-e89b-12d3-a456-426614174000"
+		}
+
+		IDVal := paramValue
+		if err := apigen.ValidateFormatUUID(IDVal, "id"); err != nil {
+			return err
+		}
+
+		res.ID = IDVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("site")
+
+		if paramValue != "" {
+
+			SiteVal := paramValue
+			if err := apigen.ValidateFormatURL(SiteVal, "site"); err != nil {
+				return err
+			}
+
+			res.Site = &SiteVal
+		}
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("ip")
+
+		if paramValue != "" {
+
+			IPVal := paramValue
+			if err := apigen.ValidateFormatIPv4(IPVal, "ip"); err != nil {
+				return err
+			}
+
+			res.IP = &IPVal
+		}
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("nick")
+		if paramValue == "" {
+			paramValue = "ann"
+		}
+
+		NickVal := paramValue
+		if len(NickVal) < minLenStringsParamsNick {
+			return apigen.FieldError{Param: "nick", Rule: "minlen", Message: "nick len must be >= 2"}
+		}
+		if len(NickVal) > maxLenStringsParamsNick {
+			return apigen.FieldError{Param: "nick", Rule: "maxlen", Message: "nick len must be <= 5"}
+		}
+
+		res.Nick = NickVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		TagsValues := params["tags"]
+
+		res.Tags = make([]string, 0, len(TagsValues))
+		for _, paramValue := range TagsValues {
+
+			TagsVal := paramValue
+			if len(TagsVal) > maxLenStringsParamsTags {
+				return apigen.FieldError{Param: "tags", Rule: "maxlen", Message: "tags len must be <= 4"}
+			}
+			if !patternStringsParamsTags.MatchString(TagsVal) {
+				return apigen.FieldError{Param: "tags", Rule: "pattern", Message: "tags must match ^[a-z]+$"}
+			}
+
+			res.Tags = append(res.Tags, TagsVal)
+		}
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
+
+const minTypesParamsScore float64 = 0.5
+const maxTypesParamsScore float64 = 9.5
+const minTypesParamsCount int64 = -5
+const maxTypesParamsCount int64 = 5
+const maxTypesParamsSize uint64 = 100
+
+var minTypesParamsSince = time.Unix(1577836800, 0)
+
+const maxLenTypesParamsTags int = 3
+const minTypesParamsIds uint64 = 1
+const maxTypesParamsLimit int = 10
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildTypesParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*TypesParams, error) {
+	res := TypesParams{}
+
+	var errs apigen.ValidationErrors
+	var err error
+
+	err = func() error {
+		paramValue := params.Get("score")
+		if paramValue == "" {
+			paramValue = "1"
+		}
+
+		ScoreVal, err := strconv.ParseFloat(paramValue, 64)
+		if err != nil || math.IsNaN(ScoreVal) || math.IsInf(ScoreVal, 0) {
+			return apigen.FieldError{Param: "score", Rule: "type", Message: "score must be float64"}
+		}
+		if !(ScoreVal >= minTypesParamsScore) {
+			return apigen.FieldError{Param: "score", Rule: "min", Message: "score must be >= 0.5"}
+		}
+		if !(ScoreVal <= maxTypesParamsScore) {
+			return apigen.FieldError{Param: "score", Rule: "max", Message: "score must be <= 9.5"}
+		}
+
+		res.Score = ScoreVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("count")
+		if paramValue == "" {
+			paramValue = "0"
+		}
+
+		CountVal, err := strconv.ParseInt(paramValue, 10, 64)
+		if err != nil {
+			return apigen.FieldError{Param: "count", Rule: "type", Message: "count must be int64"}
+		}
+		if CountVal < minTypesParamsCount {
+			return apigen.FieldError{Param: "count", Rule: "min", Message: "count must be >= -5"}
+		}
+		if CountVal > maxTypesParamsCount {
+			return apigen.FieldError{Param: "count", Rule: "max", Message: "count must be <= 5"}
+		}
+
+		res.Count = CountVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("size")
+		if paramValue == "" {
+			paramValue = "0"
+		}
+
+		SizeVal, err := strconv.ParseUint(paramValue, 10, 64)
+		if err != nil {
+			return apigen.FieldError{Param: "size", Rule: "type", Message: "size must be uint64"}
+		}
+		if SizeVal > maxTypesParamsSize {
+			return apigen.FieldError{Param: "size", Rule: "max", Message: "size must be <= 100"}
+		}
+
+		res.Size = SizeVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("level")
+		if paramValue == "" {
+			paramValue = "1"
+		}
+
+		LevelVal, err := strconv.Atoi(paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: "level", Rule: "type", Message: "level must be int"}
+		}
+
+		switch LevelVal {
+		case 1, 2:
+		default:
+			return apigen.FieldError{Param: "level", Rule: "enum", Message: "level must be one of [1, 2]"}
+		}
+
+		res.Level = LevelVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("active")
+		if paramValue == "" {
+			paramValue = "false"
+		}
+
+		ActiveVal, err := strconv.ParseBool(paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: "active", Rule: "type", Message: "active must be bool"}
+		}
+
+		res.Active = ActiveVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("since")
+		if paramValue == "" {
+			paramValue = "2020-01-01T00:00:00Z"
+		}
+
+		SinceVal, err := time.Parse(time.RFC3339, paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: "since", Rule: "type", Message: "since must be RFC3339 time"}
+		}
+		if SinceVal.Before(minTypesParamsSince) {
+			return apigen.FieldError{Param: "since", Rule: "min", Message: "since must be >= 2020-01-01T00:00:00Z"}
+		}
+
+		res.Since = SinceVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("period")
+		if paramValue == "" {
+			paramValue = "1m"
+		}
+
+		PeriodVal, err := time.ParseDuration(paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: "period", Rule: "type", Message: "period must be duration"}
+		}
+
+		switch PeriodVal {
+		case 60000000000, 90000000000:
+		default:
+			return apigen.FieldError{Param: "period", Rule: "enum", Message: "period must be one of [1m, 90s]"}
+		}
+
+		res.Period = PeriodVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		TagsValues := params["tags"]
+
+		res.Tags = make([]string, 0, len(TagsValues))
+		for _, paramValue := range TagsValues {
+
+			TagsVal := paramValue
+			if len(TagsVal) > maxLenTypesParamsTags {
+				return apigen.FieldError{Param: "tags", Rule: "maxlen", Message: "tags len must be <= 3"}
+			}
+
+			res.Tags = append(res.Tags, TagsVal)
+		}
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		IdsValues := params["ids"]
+
+		res.Ids = make([]uint64, 0, len(IdsValues))
+		for _, paramValue := range IdsValues {
+
+			IdsVal, err := strconv.ParseUint(paramValue, 10, 64)
+			if err != nil {
+				return apigen.FieldError{Param: "ids", Rule: "type", Message: "ids must be uint64"}
+			}
+			if IdsVal < minTypesParamsIds {
+				return apigen.FieldError{Param: "ids", Rule: "min", Message: "ids must be >= 1"}
+			}
+
+			res.Ids = append(res.Ids, IdsVal)
+		}
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("note")
+
+		if paramValue != "" {
+
+			NoteVal := paramValue
+
+			res.Note = &NoteVal
+		}
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("limit")
+
+		if paramValue != "" {
+
+			LimitVal, err := strconv.Atoi(paramValue)
+			if err != nil {
+				return apigen.FieldError{Param: "limit", Rule: "type", Message: "limit must be int"}
+			}
+			if LimitVal > maxTypesParamsLimit {
+				return apigen.FieldError{Param: "limit", Rule: "max", Message: "limit must be <= 10"}
+			}
+
+			res.Limit = &LimitVal
+		}
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
+
+const minUserParamsLogin int = 3
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildUserParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*UserParams, error) {
+	res := UserParams{}
+
+	var errs apigen.ValidationErrors
+	var err error
+
+	err = func() error {
+		paramValue := params.Get("login")
+		if paramValue == "" {
+			return apigen.FieldError{Param: "login", Rule: "required", Message: "login must me not empty"}
+		}
+
+		LoginVal := paramValue
+		if len(LoginVal) < minUserParamsLogin {
+			return apigen.FieldError{Param: "login", Rule: "min", Message: "login len must be >= 3"}
+		}
+
+		res.Login = LoginVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildWhoParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*WhoParams, error) {
+	res := WhoParams{}
+	return &res, nil
+}
+
+const minDtoFindParamsLimit int = 1
+const maxDtoFindParamsLimit int = 10
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildDtoFindParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*dto.FindParams, error) {
+	res := dto.FindParams{}
+
+	var errs apigen.ValidationErrors
+	var err error
+
+	err = func() error {
+		paramValue := params.Get("name")
+		if paramValue == "" {
+			return apigen.FieldError{Param: "name", Rule: "required", Message: "name must me not empty"}
+		}
+
+		NameVal := paramValue
+
+		res.Name = NameVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("limit")
+		if paramValue == "" {
+			paramValue = "5"
+		}
+
+		LimitVal, err := strconv.Atoi(paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: "limit", Rule: "type", Message: "limit must be int"}
+		}
+		if LimitVal < minDtoFindParamsLimit {
+			return apigen.FieldError{Param: "limit", Rule: "min", Message: "limit must be >= 1"}
+		}
+		if LimitVal > maxDtoFindParamsLimit {
+			return apigen.FieldError{Param: "limit", Rule: "max", Message: "limit must be <= 10"}
+		}
+
+		res.Limit = LimitVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
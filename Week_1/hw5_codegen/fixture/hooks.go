@@ -0,0 +1,50 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Проверки validate=
+type HooksApi struct {
+	MaxYear int
+}
+
+type HookParams struct {
+	Login string     `apivalidator:"required,validate=checkLogin"`
+	Year  int        `apivalidator:"default=2000,validate=checkYear"`
+	When  *time.Time `apivalidator:"validate=checkWhen"`
+	Tags  []string   `apivalidator:"paramname=tag,validate=checkLogin"`
+}
+
+func checkLogin(value string) error {
+	if value == "taken" {
+		return fmt.Errorf("login %s already exists", value)
+	}
+	return nil
+}
+
+// Функция пакета с тем же именем важнее, этот метод не вызывается
+func (api *HooksApi) checkLogin(value string) error {
+	return fmt.Errorf("method checkLogin is called")
+}
+
+func (api *HooksApi) checkYear(ctx context.Context, year int) error {
+	if year > api.MaxYear {
+		return fmt.Errorf("year must be <= %d", api.MaxYear)
+	}
+	return nil
+}
+
+func checkWhen(ctx context.Context, t time.Time) error {
+	if ctx == nil || t.Year() < 2000 {
+		return fmt.Errorf("too old")
+	}
+	return nil
+}
+
+// apigen:api {"url": "/hook"}
+func (api *HooksApi) Hook(ctx context.Context, in HookParams) (*HookParams, error) {
+	return &in, nil
+}
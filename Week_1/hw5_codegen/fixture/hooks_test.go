@@ -0,0 +1,28 @@
+package fixture
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	runCases(t, &HooksApi{MaxYear: 2010}, []Case{
+		{Path: "/hook?login=ann&year=2005&when=2020-01-01T00:00:00Z&tag=go&tag=sql", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Login":"ann","Year":2005,"When":"2020-01-01T00:00:00Z","Tags":["go","sql"]}}`},
+		// функция пакета
+		{Path: "/hook?login=taken", Status: http.StatusBadRequest,
+			Result: `{"error":"login taken already exists"}`},
+		// проверка вызывается после правил тега
+		{Path: "/hook", Status: http.StatusBadRequest,
+			Result: `{"error":"login must me not empty"}`},
+		// метод обработчика с ctx
+		{Path: "/hook?login=ann&year=2011", Status: http.StatusBadRequest,
+			Result: `{"error":"year must be <= 2010"}`},
+		// функция пакета с ctx, для *T - только если параметр пришёл
+		{Path: "/hook?login=ann&when=1999-12-31T00:00:00Z", Status: http.StatusBadRequest,
+			Result: `{"error":"too old"}`},
+		// для слайса - каждый элемент
+		{Path: "/hook?login=ann&tag=go&tag=taken", Status: http.StatusBadRequest,
+			Result: `{"error":"login taken already exists"}`},
+	})
+}
@@ -0,0 +1,50 @@
+package fixture
+
+import (
+	"context"
+	"net/http"
+
+	apigen "github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/apigen/runtime"
+)
+
+// Middleware вокруг проверки параметров и вызова метода
+type MiddlewareApi struct{}
+
+type ChainParams struct {
+	Name string `apivalidator:"required"`
+}
+
+type chainKey struct{}
+
+// Дописывает себя в X-Chain и в цепочку в контексте
+func chainStep(name string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("X-Chain", name)
+		chain, _ := r.Context().Value(chainKey{}).([]string)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), chainKey{}, append(chain, name))))
+	})
+}
+
+func outer(next http.Handler) http.Handler {
+	return chainStep("outer", next)
+}
+
+// Middleware может быть переменной пакета
+var inner = func(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// авторизация уже пройдена
+		principal, ok := apigen.PrincipalFromContext(r.Context())
+		if !ok || r.URL.Query().Get("block") != "" {
+			http.Error(w, "blocked", http.StatusTeapot)
+			return
+		}
+		w.Header().Set("X-Principal", principal.ID)
+		chainStep("inner", next).ServeHTTP(w, r)
+	})
+}
+
+// apigen:api {"url": "/chain", "auth": true, "middleware": ["outer", "inner"]}
+func (api *MiddlewareApi) Chain(ctx context.Context, in ChainParams) ([]string, error) {
+	chain, _ := ctx.Value(chainKey{}).([]string)
+	return append(chain, in.Name), nil
+}
@@ -0,0 +1,53 @@
+package fixture
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	cases := []struct {
+		Path    string
+		Auth    bool
+		Status  int
+		Chain   []string
+		Headers map[string]string
+		Result  string
+	}{
+		// первая в списке - внешняя
+		{"/chain?name=ann", true, http.StatusOK, []string{"outer", "inner"}, map[string]string{"X-Principal": "x-auth"},
+			`{"error":"","response":["outer","inner","ann"]}`},
+		// параметры проверяются внутри middleware
+		{"/chain", true, http.StatusBadRequest, []string{"outer", "inner"}, nil,
+			`{"error":"name must me not empty"}`},
+		// middleware может ответить сам, метод не вызывается
+		{"/chain?name=ann&block=1", true, http.StatusTeapot, []string{"outer"}, nil, "blocked\n"},
+		// авторизация - до middleware
+		{"/chain?name=ann", false, http.StatusForbidden, nil, nil, `{"error":"unauthorized"}`},
+	}
+
+	for idx, item := range cases {
+		req := httptest.NewRequest(http.MethodGet, item.Path, nil)
+		if item.Auth {
+			req.Header.Set("X-Auth", "100500")
+		}
+		w := httptest.NewRecorder()
+		(&MiddlewareApi{}).ServeHTTP(w, req)
+
+		body, _ := ioutil.ReadAll(w.Result().Body)
+		if w.Code != item.Status || !sameBody(body, item.Result) {
+			t.Errorf("[%d] %s: expected %d %s, got %d %s", idx, item.Path, item.Status, item.Result, w.Code, body)
+		}
+		if chain := w.Header()["X-Chain"]; !reflect.DeepEqual(chain, item.Chain) {
+			t.Errorf("[%d] %s: expected X-Chain %v, got %v", idx, item.Path, item.Chain, chain)
+		}
+		for key, value := range item.Headers {
+			if got := w.Header().Get(key); got != value {
+				t.Errorf("[%d] %s: expected %s %q, got %q", idx, item.Path, key, value, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package fixture
+
+import (
+	"context"
+
+	"github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/fixture/dto"
+)
+
+// Встроенные и вложенные структуры параметров
+type NestedApi struct{}
+
+type NestedFilter struct {
+	Name string `apivalidator:"required"`
+	Age  *int   `apivalidator:"min=1"`
+}
+
+type NestedParams struct {
+	Query string `apivalidator:"required,paramname=q"`
+	dto.Pagination
+	Filter NestedFilter
+	Extra  *NestedFilter `apivalidator:"paramname=x"`
+}
+
+// apigen:api {"url": "/nested"}
+func (api *NestedApi) List(ctx context.Context, in NestedParams) (*NestedParams, error) {
+	return &in, nil
+}
@@ -0,0 +1,39 @@
+package fixture
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNestedParams(t *testing.T) {
+	jsonBody := map[string]string{"Content-Type": "application/json"}
+	runCases(t, &NestedApi{}, []Case{
+		// поля встроенной dto.Pagination - параметры без префикса, вложенные - filter.* и x.*
+		{Path: "/nested?q=go&page=2&per_page=20&filter.name=ann&filter.age=30&x.name=bob", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Query":"go","Page":2,"PerPage":20,` +
+				`"Filter":{"Name":"ann","Age":30},"Extra":{"Name":"bob","Age":null}}}`},
+		// *NestedFilter создаётся всегда, поэтому его required тоже проверяется
+		{Path: "/nested?q=go&filter.name=ann", Status: http.StatusBadRequest,
+			Result: `{"error":"x.name must me not empty"}`},
+		{Path: "/nested?q=go&x.name=bob", Status: http.StatusBadRequest,
+			Result: `{"error":"filter.name must me not empty"}`},
+		// ключ без префикса во вложенную структуру не попадает
+		{Path: "/nested?q=go&name=ann&x.name=bob", Status: http.StatusBadRequest,
+			Result: `{"error":"filter.name must me not empty"}`},
+		{Path: "/nested?q=go&filter.name=ann&filter.age=0&x.name=bob", Status: http.StatusBadRequest,
+			Result: `{"error":"filter.age must be >= 1"}`},
+		{Path: "/nested?q=go&page=0&filter.name=ann&x.name=bob", Status: http.StatusBadRequest,
+			Result: `{"error":"page must be >= 1"}`},
+
+		// в JSON вложенные структуры - объекты
+		{Method: http.MethodPost, Path: "/nested", Headers: jsonBody,
+			Body:   `{"q": "go", "page": 3, "filter": {"name": "ann", "age": 30}, "x": {"name": "bob", "age": 7}}`,
+			Status: http.StatusOK,
+			Result: `{"error":"","response":{"Query":"go","Page":3,"PerPage":10,` +
+				`"Filter":{"Name":"ann","Age":30},"Extra":{"Name":"bob","Age":7}}}`},
+		{Method: http.MethodPost, Path: "/nested", Headers: jsonBody,
+			Body:   `{"q": "go", "filter": {"name": "ann", "age": 0}, "x": {"name": "bob"}}`,
+			Status: http.StatusBadRequest,
+			Result: `{"error":"filter.age must be >= 1"}`},
+	})
+}
@@ -0,0 +1,10 @@
+package perfile
+
+import "context"
+
+type OrdersApi struct{}
+
+// apigen:api {"url": "/orders"}
+func (api *OrdersApi) List(ctx context.Context, in ListParams) (*ListParams, error) {
+	return &in, nil
+}
@@ -0,0 +1,203 @@
+package perfile
+
+import (
+	"context"
+	"errors"
+	apigen "github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/apigen/runtime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// auto-generated file: do not edit!
+
+// Не скомпилируется с версией apigen/runtime, в которой нет API этого файла
+const _ = apigen.APIVersion8
+
+func (h *OrdersApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/orders":
+		h.wrapperList(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *OrdersApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *OrdersApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
+}
+
+func (h *OrdersApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *OrdersApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *OrdersApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *OrdersApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *OrdersApi) wrapperList(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/orders", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "OrdersApi.List", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleList(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *OrdersApi) handleList(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/orders")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildListParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/orders")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.List(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "OrdersApi.List", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+var patternListParamsQuery = regexp.MustCompile("^[a-z]*$")
+
+const minListParamsLimit int = 1
+const maxListParamsLimit int = 50
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildListParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*ListParams, error) {
+	res := ListParams{}
+
+	var errs apigen.ValidationErrors
+	var err error
+
+	err = func() error {
+		paramValue := params.Get("limit")
+		if paramValue == "" {
+			paramValue = "10"
+		}
+
+		LimitVal, err := strconv.Atoi(paramValue)
+		if err != nil {
+			return apigen.FieldError{Param: "limit", Rule: "type", Message: "limit must be int"}
+		}
+		if LimitVal < minListParamsLimit {
+			return apigen.FieldError{Param: "limit", Rule: "min", Message: "limit must be >= 1"}
+		}
+		if LimitVal > maxListParamsLimit {
+			return apigen.FieldError{Param: "limit", Rule: "max", Message: "limit must be <= 50"}
+		}
+
+		res.Limit = LimitVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	err = func() error {
+		paramValue := params.Get("query")
+
+		QueryVal := paramValue
+		if !patternListParamsQuery.MatchString(QueryVal) {
+			return apigen.FieldError{Param: "query", Rule: "pattern", Message: "query must match ^[a-z]*$"}
+		}
+
+		res.Query = QueryVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
@@ -0,0 +1,18 @@
+// Package perfile - обработчики для тестов запуска генератора для каждого файла пакета отдельно:
+//
+//	go build handlers_gen/* && ./codegen fixture/perfile/orders.go fixture/perfile/orders_handlers.go
+//	go build handlers_gen/* && ./codegen fixture/perfile/users.go fixture/perfile/users_handlers.go
+//	go build handlers_gen/* && ./codegen fixture/perfile/users_admin.go fixture/perfile/users_admin_handlers.go
+//
+// ListParams принимают методы двух файлов, её validateAndBuild попадает только в orders_handlers.go.
+// Методы UsersApi объявлены в users.go и users_admin.go, его ServeHTTP - только в users_handlers.go
+package perfile
+
+type ApiError struct {
+	HTTPStatus int
+	Err        error
+}
+
+func (ae ApiError) Error() string {
+	return ae.Err.Error()
+}
@@ -0,0 +1,62 @@
+package perfile
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// validateAndBuildListParams из orders_handlers.go используют обработчики обоих файлов
+func TestSharedParams(t *testing.T) {
+	cases := []struct {
+		Handler http.Handler
+		Path    string
+		Status  int
+		Result  string
+	}{
+		{&OrdersApi{}, "/orders?limit=5&query=abc", http.StatusOK, `{"error":"","response":{"Limit":5,"Query":"abc"}}`},
+		{&UsersApi{}, "/users?limit=5&query=abc", http.StatusOK, `{"error":"","response":{"Limit":5,"Query":"abc"}}`},
+		{&UsersApi{}, "/users?limit=51", http.StatusBadRequest, `{"error":"limit must be \u003c= 50"}`},
+		{&OrdersApi{}, "/orders?query=ABC", http.StatusBadRequest, `{"error":"query must match ^[a-z]*$"}`},
+		{&UsersApi{}, "/user?login=ann", http.StatusOK, `{"error":"","response":{"Login":"ann"}}`},
+		{&OrdersApi{}, "/user?login=ann", http.StatusNotFound, `{"error":"unknown method"}`},
+	}
+
+	for idx, item := range cases {
+		req := httptest.NewRequest(http.MethodGet, item.Path, nil)
+		w := httptest.NewRecorder()
+		item.Handler.ServeHTTP(w, req)
+
+		body, _ := ioutil.ReadAll(w.Result().Body)
+		if w.Code != item.Status || string(body) != item.Result {
+			t.Errorf("[%d] %s: expected %d %s, got %d %s", idx, item.Path, item.Status, item.Result, w.Code, body)
+		}
+	}
+}
+
+// ServeHTTP из users_handlers.go ведёт и на обёртку из users_admin_handlers.go
+func TestSplitHandler(t *testing.T) {
+	cases := []struct {
+		Method string
+		Path   string
+		Status int
+		Result string
+	}{
+		{http.MethodPost, "/user/block?login=ann", http.StatusOK, `{"error":"","response":{"Login":"ann"}}`},
+		{http.MethodPost, "/user/block", http.StatusBadRequest, `{"error":"login must me not empty"}`},
+		{http.MethodGet, "/user/block?login=ann", http.StatusNotAcceptable, `{"error":"bad method"}`},
+		{http.MethodGet, "/user?login=ann", http.StatusOK, `{"error":"","response":{"Login":"ann"}}`},
+	}
+
+	for idx, item := range cases {
+		req := httptest.NewRequest(item.Method, item.Path, nil)
+		w := httptest.NewRecorder()
+		(&UsersApi{}).ServeHTTP(w, req)
+
+		body, _ := ioutil.ReadAll(w.Result().Body)
+		if w.Code != item.Status || string(body) != item.Result {
+			t.Errorf("[%d] %s %s: expected %d %s, got %d %s", idx, item.Method, item.Path, item.Status, item.Result, w.Code, body)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package perfile
+
+import "context"
+
+type UsersApi struct{}
+
+type ListParams struct {
+	Limit int    `apivalidator:"min=1,max=50,default=10"`
+	Query string `apivalidator:"pattern=^[a-z]*$"`
+}
+
+type UserParams struct {
+	Login string `apivalidator:"required"`
+}
+
+// apigen:api {"url": "/users"}
+func (api *UsersApi) List(ctx context.Context, in ListParams) (*ListParams, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/user"}
+func (api *UsersApi) Find(ctx context.Context, in UserParams) (*UserParams, error) {
+	return &in, nil
+}
@@ -0,0 +1,14 @@
+package perfile
+
+import "context"
+
+type BlockParams struct {
+	Login string `apivalidator:"required"`
+}
+
+// Метод UsersApi в другом файле: ServeHTTP с маршрутом /user/block генерируется в users_handlers.go
+//
+// apigen:api {"url": "/user/block", "method": "POST"}
+func (api *UsersApi) Block(ctx context.Context, in BlockParams) (*BlockParams, error) {
+	return &in, nil
+}
@@ -0,0 +1,126 @@
+package perfile
+
+import (
+	"context"
+	"errors"
+	apigen "github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/apigen/runtime"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// auto-generated file: do not edit!
+
+// Не скомпилируется с версией apigen/runtime, в которой нет API этого файла
+const _ = apigen.APIVersion8
+
+func (h *UsersApi) wrapperBlock(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/user/block", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "UsersApi.Block", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "POST, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	switch r.Method {
+	case "POST":
+	default:
+		w.Header().Set("Allow", "POST, OPTIONS")
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("bad method"))
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleBlock(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *UsersApi) handleBlock(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/user/block")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildBlockParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/user/block")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Block(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "UsersApi.Block", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildBlockParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*BlockParams, error) {
+	res := BlockParams{}
+
+	var errs apigen.ValidationErrors
+	var err error
+
+	err = func() error {
+		paramValue := params.Get("login")
+		if paramValue == "" {
+			return apigen.FieldError{Param: "login", Rule: "required", Message: "login must me not empty"}
+		}
+
+		LoginVal := paramValue
+
+		res.Login = LoginVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
@@ -0,0 +1,245 @@
+package perfile
+
+import (
+	"context"
+	"errors"
+	apigen "github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/apigen/runtime"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// auto-generated file: do not edit!
+
+// Не скомпилируется с версией apigen/runtime, в которой нет API этого файла
+const _ = apigen.APIVersion8
+
+func (h *UsersApi) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/user/block":
+		h.wrapperBlock(w, r, nil)
+	case "/user":
+		h.wrapperFind(w, r, nil)
+	case "/users":
+		h.wrapperList(w, r, nil)
+	default:
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
+	}
+}
+
+func (h *UsersApi) getAuthenticator() apigen.Authenticator {
+	if v, ok := interface{}(h).(apigen.Authenticator); ok {
+		return v
+	}
+	return apigen.DefaultAuthenticator
+}
+
+func (h *UsersApi) getEncoder() apigen.Encoder {
+	if v, ok := interface{}(h).(apigen.Encoder); ok {
+		return v
+	}
+	return apigen.DefaultEncoder
+}
+
+func (h *UsersApi) getLogger() apigen.Logger {
+	if v, ok := interface{}(h).(apigen.Logger); ok {
+		return v
+	}
+	return apigen.DefaultLogger
+}
+
+func (h *UsersApi) getMetrics() apigen.Metrics {
+	if v, ok := interface{}(h).(apigen.Metrics); ok {
+		return v
+	}
+	return apigen.DefaultMetrics
+}
+
+func (h *UsersApi) getLimiter() apigen.Limiter {
+	if v, ok := interface{}(h).(apigen.Limiter); ok {
+		return v
+	}
+	return apigen.DefaultLimiter
+}
+
+func (h *UsersApi) getCache() apigen.Cache {
+	if v, ok := interface{}(h).(apigen.Cache); ok {
+		return v
+	}
+	return apigen.DefaultCache
+}
+
+func (h *UsersApi) wrapperFind(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/user", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "UsersApi.Find", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleFind(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *UsersApi) handleFind(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/user")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildUserParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/user")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.Find(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "UsersApi.Find", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+func (h *UsersApi) wrapperList(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest("/users", sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
+	ctx := r.Context()
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "UsersApi.List", p)
+		}
+	}()
+
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "GET, HEAD, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+
+	r = r.WithContext(ctx)
+	h.handleList(w, r, encoder, pathParams)
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *UsersApi) handleList(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure("/users")
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+	p0, err := validateAndBuildListParams(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure("/users")
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	res, err := h.List(
+		ctx,
+		*p0,
+	)
+
+	if err != nil {
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		} else {
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "UsersApi.List", Status: status, Err: err})
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+
+	encoder.EncodeResponse(w, r, res)
+}
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuildUserParams(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*UserParams, error) {
+	res := UserParams{}
+
+	var errs apigen.ValidationErrors
+	var err error
+
+	err = func() error {
+		paramValue := params.Get("login")
+		if paramValue == "" {
+			return apigen.FieldError{Param: "login", Rule: "required", Message: "login must me not empty"}
+		}
+
+		LoginVal := paramValue
+
+		res.Login = LoginVal
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &res, nil
+}
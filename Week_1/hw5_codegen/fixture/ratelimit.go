@@ -0,0 +1,68 @@
+package fixture
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	apigen "github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/apigen/runtime"
+)
+
+// Методы с "ratelimit". Свои корзины и авторизация: X-User - ID автора, X-Roles - его роли
+type RateLimitApi struct {
+	limiter apigen.MemoryLimiter
+	// Сколько раз вызван каждый метод
+	Calls map[string]int
+}
+
+func (api *RateLimitApi) Allow(key string, rate apigen.Rate) (bool, time.Duration) {
+	return api.limiter.Allow(key, rate)
+}
+
+func (api *RateLimitApi) Authenticate(r *http.Request) (*apigen.Principal, error) {
+	id := r.Header.Get("X-User")
+	if id == "" {
+		return nil, ApiError{http.StatusUnauthorized, errors.New("no user")}
+	}
+	principal := &apigen.Principal{ID: id}
+	if roles := r.Header.Get("X-Roles"); roles != "" {
+		principal.Roles = strings.Split(roles, ",")
+	}
+	return principal, nil
+}
+
+func (api *RateLimitApi) called(name string) {
+	if api.Calls == nil {
+		api.Calls = map[string]int{}
+	}
+	api.Calls[name]++
+}
+
+// apigen:api {"url": "/limited/ip", "ratelimit": "2/m"}
+func (api *RateLimitApi) PerIP(ctx context.Context, in WhoParams) (*WhoParams, error) {
+	api.called("PerIP")
+	return &in, nil
+}
+
+// apigen:api {"url": "/limited/auth", "auth": true, "roles": ["admin"], "ratelimit": {"per": "auth", "rate": "2/m"}}
+func (api *RateLimitApi) PerAuth(ctx context.Context, in WhoParams) (*WhoParams, error) {
+	api.called("PerAuth")
+	return &in, nil
+}
+
+// Обработчики с одним url и общим apigen.DefaultLimiter
+type SameURLLimitApi struct{}
+
+type OtherSameURLLimitApi struct{}
+
+// apigen:api {"url": "/limited/same", "ratelimit": "1/m"}
+func (api *SameURLLimitApi) Ping(ctx context.Context, in WhoParams) (*WhoParams, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/limited/same", "ratelimit": "1/m"}
+func (api *OtherSameURLLimitApi) Ping(ctx context.Context, in WhoParams) (*WhoParams, error) {
+	return &in, nil
+}
@@ -0,0 +1,71 @@
+package fixture
+
+import (
+	"net/http"
+	"testing"
+
+	apigen "github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/apigen/runtime"
+)
+
+func TestRateLimitPerIP(t *testing.T) {
+	api := &RateLimitApi{}
+	limited := map[string]string{"Retry-After": "30"}
+	runCases(t, api, []Case{
+		{Path: "/limited/ip", Status: http.StatusOK, Result: `{"error":"","response":{}}`},
+		{Path: "/limited/ip", Status: http.StatusOK, Result: `{"error":"","response":{}}`},
+		// 2/m: следующий запрос - через 30 секунд
+		{Path: "/limited/ip", Status: http.StatusTooManyRequests,
+			Result: `{"error":"too many requests"}`, ResultHeaders: limited},
+		// ID автора не меняет ключ по IP
+		{Path: "/limited/ip", Headers: map[string]string{"X-User": "bob"}, Status: http.StatusTooManyRequests,
+			Result: `{"error":"too many requests"}`, ResultHeaders: limited},
+	})
+	if api.Calls["PerIP"] != 2 {
+		t.Errorf("expected PerIP to be called 2 times, got %d", api.Calls["PerIP"])
+	}
+}
+
+func TestRateLimitPerAuth(t *testing.T) {
+	api := &RateLimitApi{}
+	admin := func(id string) map[string]string {
+		return map[string]string{"X-User": id, "X-Roles": "admin"}
+	}
+	runCases(t, api, []Case{
+		// отказы авторизации и проверки ролей не тратят запросы
+		{Path: "/limited/auth", Status: http.StatusUnauthorized, Result: `{"error":"no user"}`},
+		{Path: "/limited/auth", Headers: map[string]string{"X-User": "ann"}, Status: http.StatusForbidden,
+			Result: `{"error":"forbidden"}`},
+		{Path: "/limited/auth", Headers: map[string]string{"X-User": "ann"}, Status: http.StatusForbidden,
+			Result: `{"error":"forbidden"}`},
+		{Path: "/limited/auth", Headers: map[string]string{"X-User": "ann"}, Status: http.StatusForbidden,
+			Result: `{"error":"forbidden"}`},
+		{Path: "/limited/auth", Headers: admin("ann"), Status: http.StatusOK, Result: `{"error":"","response":{}}`},
+		{Path: "/limited/auth", Headers: admin("ann"), Status: http.StatusOK, Result: `{"error":"","response":{}}`},
+		{Path: "/limited/auth", Headers: admin("ann"), Status: http.StatusTooManyRequests,
+			Result: `{"error":"too many requests"}`, ResultHeaders: map[string]string{"Retry-After": "30"}},
+		// у другого автора с того же IP своя корзина
+		{Path: "/limited/auth", Headers: admin("bob"), Status: http.StatusOK, Result: `{"error":"","response":{}}`},
+		// корзины разных методов не общие
+		{Path: "/limited/ip", Headers: admin("ann"), Status: http.StatusOK, Result: `{"error":"","response":{}}`},
+	})
+	if api.Calls["PerAuth"] != 3 {
+		t.Errorf("expected PerAuth to be called 3 times, got %d", api.Calls["PerAuth"])
+	}
+}
+
+func TestRateLimitSameURL(t *testing.T) {
+	defaultLimiter := apigen.DefaultLimiter
+	apigen.DefaultLimiter = &apigen.MemoryLimiter{}
+	defer func() { apigen.DefaultLimiter = defaultLimiter }()
+
+	limited := `{"error":"too many requests"}`
+	runCases(t, &SameURLLimitApi{}, []Case{
+		{Path: "/limited/same", Status: http.StatusOK, Result: `{"error":"","response":{}}`},
+		{Path: "/limited/same", Status: http.StatusTooManyRequests, Result: limited},
+	})
+	// корзина другого обработчика с тем же url и IP не потрачена
+	runCases(t, &OtherSameURLLimitApi{}, []Case{
+		{Path: "/limited/same", Status: http.StatusOK, Result: `{"error":"","response":{}}`},
+		{Path: "/limited/same", Status: http.StatusTooManyRequests, Result: limited},
+	})
+}
@@ -0,0 +1,29 @@
+package fixture
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+type FindParams struct {
+	Login string `apivalidator:"required"`
+}
+
+// Ошибки с ID запроса в теле
+//
+// apigen:handler {"requestId": true}
+type RequestIDApi struct{}
+
+// apigen:api {"url": "/find"}
+func (api *RequestIDApi) Find(ctx context.Context, in FindParams) (*FindParams, error) {
+	return nil, ApiError{http.StatusNotFound, errors.New("user not exist")}
+}
+
+// apigen:handler {"encoder": "problem", "requestId": true}
+type ProblemRequestIDApi struct{}
+
+// apigen:api {"url": "/find"}
+func (api *ProblemRequestIDApi) Find(ctx context.Context, in FindParams) (*FindParams, error) {
+	return nil, ApiError{http.StatusNotFound, errors.New("user not exist")}
+}
@@ -0,0 +1,59 @@
+package fixture
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestIDInErrorBody(t *testing.T) {
+	cases := []struct {
+		Handler http.Handler
+		Path    string
+		Headers map[string]string
+		Status  int
+	}{
+		// ID создан обёрткой
+		{&RequestIDApi{}, "/find?login=nobody", nil, http.StatusNotFound},
+		{&RequestIDApi{}, "/find", nil, http.StatusBadRequest},
+		{&RequestIDApi{}, "/find?login=nobody", map[string]string{"X-Request-ID": "support-42"}, http.StatusNotFound},
+		{&RequestIDApi{}, "/find?login=nobody",
+			map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}, http.StatusNotFound},
+		{&ProblemRequestIDApi{}, "/find?login=nobody", nil, http.StatusNotFound},
+		{&ProblemRequestIDApi{}, "/find", map[string]string{"X-Request-ID": "support-42"}, http.StatusBadRequest},
+	}
+
+	for idx, item := range cases {
+		ts := httptest.NewServer(item.Handler)
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+item.Path, nil)
+		for key, value := range item.Headers {
+			req.Header.Set(key, value)
+		}
+		resp, err := ts.Client().Do(req)
+		if err != nil {
+			ts.Close()
+			t.Errorf("[%d] request error: %v", idx, err)
+			continue
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		ts.Close()
+
+		if resp.StatusCode != item.Status {
+			t.Errorf("[%d] expected http status %v, got %v", idx, item.Status, resp.StatusCode)
+		}
+		var result struct {
+			RequestID string `json:"request_id"`
+		}
+		if err := json.Unmarshal(body, &result); err != nil {
+			t.Errorf("[%d] bad body %s: %v", idx, body, err)
+			continue
+		}
+		id := resp.Header.Get("X-Request-ID")
+		if id == "" || result.RequestID != id {
+			t.Errorf("[%d] expected request_id %q in body, got %s", idx, id, body)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package fixture
+
+import "context"
+
+// Параметры пути
+type RoutesApi struct{}
+
+type UserParams struct {
+	Login string `apivalidator:"required,min=3"`
+}
+
+type ItemParams struct {
+	ID int `apivalidator:"paramname=id,min=-10"`
+}
+
+type BlockParams struct {
+	Height uint64 `apivalidator:"paramname=n"`
+}
+
+// apigen:api {"url": "/users/me"}
+func (api *RoutesApi) Me(ctx context.Context, in WhoParams) (*UserParams, error) {
+	return &UserParams{Login: "me"}, nil
+}
+
+// apigen:api {"url": "/users/{login}"}
+func (api *RoutesApi) User(ctx context.Context, in UserParams) (*UserParams, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/users/{login}/profile"}
+func (api *RoutesApi) Profile(ctx context.Context, in UserParams) (*UserParams, error) {
+	return &UserParams{Login: "profile " + in.Login}, nil
+}
+
+// apigen:api {"url": "/items/{id:int}"}
+func (api *RoutesApi) Item(ctx context.Context, in ItemParams) (*ItemParams, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/blocks/{n:uint}"}
+func (api *RoutesApi) Block(ctx context.Context, in BlockParams) (*BlockParams, error) {
+	return &in, nil
+}
+
+type SlugParams struct {
+	Slug string `apivalidator:"required"`
+}
+
+// /pages/{n:uint} уже /pages/{slug} и проверяется раньше, хотя Article раньше Page по имени
+//
+// apigen:api {"url": "/pages/{slug}"}
+func (api *RoutesApi) Article(ctx context.Context, in SlugParams) (*SlugParams, error) {
+	return &in, nil
+}
+
+// apigen:api {"url": "/pages/{n:uint}"}
+func (api *RoutesApi) Page(ctx context.Context, in BlockParams) (*BlockParams, error) {
+	return &in, nil
+}
@@ -0,0 +1,56 @@
+package fixture
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRoutes(t *testing.T) {
+	runCases(t, &RoutesApi{}, []Case{
+		// url без параметров проверяется раньше /users/{login}
+		{Path: "/users/me", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Login":"me"}}`},
+		{Path: "/users/ann", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Login":"ann"}}`},
+		{Path: "/users/ann/profile", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Login":"profile ann"}}`},
+		// значение из пути проверяется правилами поля
+		{Path: "/users/an", Status: http.StatusBadRequest,
+			Result: `{"error":"login len must be >= 3"}`},
+
+		// путь важнее query и тела
+		{Path: "/users/ann?login=bob", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Login":"ann"}}`},
+		{Method: http.MethodPost, Path: "/users/ann", Body: "login=bob", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Login":"ann"}}`},
+
+		{Path: "/items/42", Status: http.StatusOK,
+			Result: `{"error":"","response":{"ID":42}}`},
+		{Path: "/items/-3", Status: http.StatusOK,
+			Result: `{"error":"","response":{"ID":-3}}`},
+		{Path: "/items/-11", Status: http.StatusBadRequest,
+			Result: `{"error":"id must be >= -10"}`},
+		{Path: "/blocks/7", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Height":7}}`},
+
+		// значение не подходит под тип плейсхолдера - url не найден
+		{Path: "/items/abc", Status: http.StatusNotFound,
+			Result: `{"error":"unknown method"}`},
+		{Path: "/items/4.5", Status: http.StatusNotFound,
+			Result: `{"error":"unknown method"}`},
+		{Path: "/blocks/-7", Status: http.StatusNotFound,
+			Result: `{"error":"unknown method"}`},
+		{Path: "/users/ann/extra", Status: http.StatusNotFound,
+			Result: `{"error":"unknown method"}`},
+		{Path: "/users/", Status: http.StatusNotFound,
+			Result: `{"error":"unknown method"}`},
+
+		// более узкий url проверяется раньше
+		{Path: "/pages/7", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Height":7}}`},
+		{Path: "/pages/about", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Slug":"about"}}`},
+		{Path: "/pages/-7", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Slug":"-7"}}`},
+	})
+}
@@ -0,0 +1,15 @@
+package fixture
+
+import (
+	"context"
+
+	"github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/fixture/dto"
+)
+
+// Параметры метода из импортированного пакета
+type SearchApi struct{}
+
+// apigen:api {"url": "/search"}
+func (api *SearchApi) Find(ctx context.Context, in dto.FindParams) (*dto.FindParams, error) {
+	return &in, nil
+}
@@ -0,0 +1,19 @@
+package fixture
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSearchImportedParams(t *testing.T) {
+	runCases(t, &SearchApi{}, []Case{
+		{Path: "/search?name=go", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Name":"go","Limit":5}}`},
+		{Path: "/search?name=go&limit=10", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Name":"go","Limit":10}}`},
+		{Path: "/search", Status: http.StatusBadRequest,
+			Result: `{"error":"name must me not empty"}`},
+		{Path: "/search?name=go&limit=11", Status: http.StatusBadRequest,
+			Result: `{"error":"limit must be <= 10"}`},
+	})
+}
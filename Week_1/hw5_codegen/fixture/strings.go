@@ -0,0 +1,22 @@
+package fixture
+
+import "context"
+
+// Правила для строк: pattern, format, minlen и maxlen
+type StringsApi struct{}
+
+type StringsParams struct {
+	Code  string   `apivalidator:"pattern=^[A-Z]{2,3}$,default=RU"`
+	Zip   *string  `apivalidator:"pattern=^\\d{5}$"`
+	Email string   `apivalidator:"format=email,default=ann@example.com"`
+	ID    string   `apivalidator:"paramname=id,format=uuid,default=123e4567-e89b-12d3-a456-426614174000"`
+	Site  *string  `apivalidator:"format=url"`
+	IP    *string  `apivalidator:"paramname=ip,format=ipv4"`
+	Nick  string   `apivalidator:"minlen=2,maxlen=5,default=ann"`
+	Tags  []string `apivalidator:"pattern=^[a-z]+$,maxlen=4"`
+}
+
+// apigen:api {"url": "/strings"}
+func (api *StringsApi) Check(ctx context.Context, in StringsParams) (*StringsParams, error) {
+	return &in, nil
+}
@@ -0,0 +1,68 @@
+package fixture
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	apigen "github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/apigen/runtime"
+)
+
+func TestStringRules(t *testing.T) {
+	runCases(t, &StringsApi{}, []Case{
+		{Path: "/strings", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Code":"RU","Zip":null,"Email":"ann@example.com",` +
+				`"ID":"123e4567-e89b-12d3-a456-426614174000","Site":null,"IP":null,"Nick":"ann","Tags":[]}}`},
+		{Path: "/strings?code=USA&zip=12345&email=bob@example.org&id=00000000-0000-0000-0000-000000000000" +
+			"&site=https://example.org/x&ip=10.0.0.1&nick=bo&tags=go&tags=sql", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Code":"USA","Zip":"12345","Email":"bob@example.org",` +
+				`"ID":"00000000-0000-0000-0000-000000000000","Site":"https://example.org/x","IP":"10.0.0.1","Nick":"bo","Tags":["go","sql"]}}`},
+
+		// pattern со значением {2,3} внутри - одно правило
+		{Path: "/strings?code=RUSS", Status: http.StatusBadRequest,
+			Result: `{"error":"code must match ^[A-Z]{2,3}$"}`},
+		{Path: "/strings?code=ru", Status: http.StatusBadRequest,
+			Result: `{"error":"code must match ^[A-Z]{2,3}$"}`},
+		{Path: "/strings?zip=1234a", Status: http.StatusBadRequest,
+			Result: `{"error":"zip must match ^\\d{5}$"}`},
+		{Path: "/strings?tags=go&tags=Go", Status: http.StatusBadRequest,
+			Result: `{"error":"tags must match ^[a-z]+$"}`},
+		{Path: "/strings?tags=golang", Status: http.StatusBadRequest,
+			Result: `{"error":"tags len must be <= 4"}`},
+
+		{Path: "/strings?email=ann", Status: http.StatusBadRequest,
+			Result: `{"error":"email must be a valid email"}`},
+		{Path: "/strings?id=123", Status: http.StatusBadRequest,
+			Result: `{"error":"id must be a valid uuid"}`},
+		{Path: "/strings?site=example.org", Status: http.StatusBadRequest,
+			Result: `{"error":"site must be a valid url"}`},
+		{Path: "/strings?ip=::1", Status: http.StatusBadRequest,
+			Result: `{"error":"ip must be a valid ipv4"}`},
+
+		{Path: "/strings?nick=a", Status: http.StatusBadRequest,
+			Result: `{"error":"nick len must be >= 2"}`},
+		{Path: "/strings?nick=annabel", Status: http.StatusBadRequest,
+			Result: `{"error":"nick len must be <= 5"}`},
+	})
+}
+
+// Правило в FieldError - то, которое не прошло
+func TestStringRulesNames(t *testing.T) {
+	params := url.Values{
+		"code": {"ru"}, "email": {"ann"}, "ip": {"::1"}, "nick": {"a"}, "tags": {"go", "golang"},
+	}
+	_, err := validateAndBuildStringsParams(context.Background(), &StringsApi{}, params, true)
+
+	expected := apigen.ValidationErrors{
+		{Param: "code", Rule: "pattern", Message: "code must match ^[A-Z]{2,3}$"},
+		{Param: "email", Rule: "format", Message: "email must be a valid email"},
+		{Param: "ip", Rule: "format", Message: "ip must be a valid ipv4"},
+		{Param: "nick", Rule: "minlen", Message: "nick len must be >= 2"},
+		{Param: "tags", Rule: "maxlen", Message: "tags len must be <= 4"},
+	}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("expected %#v, got %#v", expected, err)
+	}
+}
@@ -0,0 +1,32 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Метод с "timeout"
+type TimeoutApi struct{}
+
+type SleepParams struct {
+	For time.Duration `apivalidator:"default=0s"`
+}
+
+// apigen:api {"url": "/sleep", "timeout": "50ms"}
+func (api *TimeoutApi) Sleep(ctx context.Context, in SleepParams) (*SleepParams, error) {
+	select {
+	case <-time.After(in.For):
+		return &in, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Ошибка, оборачивающая DeadlineExceeded, тоже даёт 504
+//
+// apigen:api {"url": "/sleep/wrapped", "timeout": "50ms"}
+func (api *TimeoutApi) SleepWrapped(ctx context.Context, in SleepParams) (*SleepParams, error) {
+	<-ctx.Done()
+	return nil, fmt.Errorf("sleep: %w", ctx.Err())
+}
@@ -0,0 +1,17 @@
+package fixture
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestTimeout(t *testing.T) {
+	runCases(t, &TimeoutApi{}, []Case{
+		{Path: "/sleep?for=1ms", Status: http.StatusOK,
+			Result: `{"error":"","response":{"For":1000000}}`},
+		{Path: "/sleep?for=1m", Status: http.StatusGatewayTimeout,
+			Result: `{"error":"context deadline exceeded"}`},
+		{Path: "/sleep/wrapped", Status: http.StatusGatewayTimeout,
+			Result: `{"error":"sleep: context deadline exceeded"}`},
+	})
+}
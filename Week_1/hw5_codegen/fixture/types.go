@@ -0,0 +1,37 @@
+package fixture
+
+import (
+	"context"
+	"time"
+)
+
+// Параметры всех поддерживаемых типов
+type TypesApi struct{}
+
+type TypesParams struct {
+	Score  float64       `apivalidator:"min=0.5,max=9.5,default=1"`
+	Count  int64         `apivalidator:"min=-5,max=5,default=0"`
+	Size   uint64        `apivalidator:"max=100,default=0"`
+	Level  int           `apivalidator:"enum=1|2,default=1"`
+	Active bool          `apivalidator:"default=false"`
+	Since  time.Time     `apivalidator:"min=2020-01-01T00:00:00Z,default=2020-01-01T00:00:00Z"`
+	Period time.Duration `apivalidator:"enum=1m|90s,default=1m"`
+	Tags   []string      `apivalidator:"maxlen=3"`
+	Ids    []uint64      `apivalidator:"min=1"`
+	Note   *string       `apivalidator:"paramname=note"`
+	Limit  *int          `apivalidator:"max=10"`
+}
+
+// apigen:api {"url": "/types"}
+func (api *TypesApi) Echo(ctx context.Context, in TypesParams) (*TypesParams, error) {
+	return &in, nil
+}
+
+type ScoreParams struct {
+	Score float64 `apivalidator:"required,min=0.5,max=9.5"`
+}
+
+// apigen:api {"url": "/score"}
+func (api *TypesApi) Score(ctx context.Context, in ScoreParams) (*ScoreParams, error) {
+	return &in, nil
+}
@@ -0,0 +1,83 @@
+package fixture
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestTypes(t *testing.T) {
+	runCases(t, &TypesApi{}, []Case{
+		// без параметров - default, *T остаются nil
+		{Path: "/types", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Score":1,"Count":0,"Size":0,"Level":1,"Active":false,` +
+				`"Since":"2020-01-01T00:00:00Z","Period":60000000000,"Tags":[],"Ids":[],"Note":null,"Limit":null}}`},
+		{Path: "/types?score=2.5&count=-5&size=100&level=2&active=true&since=2021-06-01T10:00:00%2B03:00" +
+			"&period=90s&tags=ab&tags=c&ids=1&ids=7&note=hi&limit=10", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Score":2.5,"Count":-5,"Size":100,"Level":2,"Active":true,` +
+				`"Since":"2021-06-01T10:00:00+03:00","Period":90000000000,"Tags":["ab","c"],"Ids":[1,7],"Note":"hi","Limit":10}}`},
+
+		{Path: "/types?count=6", Status: http.StatusBadRequest,
+			Result: `{"error":"count must be <= 5"}`},
+		{Path: "/types?count=1.5", Status: http.StatusBadRequest,
+			Result: `{"error":"count must be int64"}`},
+		{Path: "/types?size=-1", Status: http.StatusBadRequest,
+			Result: `{"error":"size must be uint64"}`},
+		{Path: "/types?size=101", Status: http.StatusBadRequest,
+			Result: `{"error":"size must be <= 100"}`},
+		{Path: "/types?active=yes", Status: http.StatusBadRequest,
+			Result: `{"error":"active must be bool"}`},
+		{Path: "/types?since=2019-12-31T23:59:59Z", Status: http.StatusBadRequest,
+			Result: `{"error":"since must be >= 2020-01-01T00:00:00Z"}`},
+		{Path: "/types?since=2021-06-01", Status: http.StatusBadRequest,
+			Result: `{"error":"since must be RFC3339 time"}`},
+		{Path: "/types?period=1x", Status: http.StatusBadRequest,
+			Result: `{"error":"period must be duration"}`},
+		{Path: "/types?tags=ab&tags=abcd", Status: http.StatusBadRequest,
+			Result: `{"error":"tags len must be <= 3"}`},
+		{Path: "/types?ids=1&ids=0", Status: http.StatusBadRequest,
+			Result: `{"error":"ids must be >= 1"}`},
+		{Path: "/types?ids=1&ids=x", Status: http.StatusBadRequest,
+			Result: `{"error":"ids must be uint64"}`},
+		{Path: "/types?limit=11", Status: http.StatusBadRequest,
+			Result: `{"error":"limit must be <= 10"}`},
+		{Path: "/types?limit=x", Status: http.StatusBadRequest,
+			Result: `{"error":"limit must be int"}`},
+	})
+}
+
+// enum сравнивает разобранное значение, а не строку запроса
+func TestTypesEnum(t *testing.T) {
+	runCases(t, &TypesApi{}, []Case{
+		{Path: "/types?level=02&period=60s", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Score":1,"Count":0,"Size":0,"Level":2,"Active":false,` +
+				`"Since":"2020-01-01T00:00:00Z","Period":60000000000,"Tags":[],"Ids":[],"Note":null,"Limit":null}}`},
+		{Path: "/types?period=1m30s", Status: http.StatusOK,
+			Result: `{"error":"","response":{"Score":1,"Count":0,"Size":0,"Level":1,"Active":false,` +
+				`"Since":"2020-01-01T00:00:00Z","Period":90000000000,"Tags":[],"Ids":[],"Note":null,"Limit":null}}`},
+		{Path: "/types?level=3", Status: http.StatusBadRequest,
+			Result: `{"error":"level must be one of [1, 2]"}`},
+		// сначала проверяется тип
+		{Path: "/types?level=one", Status: http.StatusBadRequest,
+			Result: `{"error":"level must be int"}`},
+		{Path: "/types?period=2m", Status: http.StatusBadRequest,
+			Result: `{"error":"period must be one of [1m, 90s]"}`},
+	})
+}
+
+func TestTypesFloat64(t *testing.T) {
+	runCases(t, &TypesApi{}, []Case{
+		{Path: "/types?score=0.4", Status: http.StatusBadRequest,
+			Result: `{"error":"score must be >= 0.5"}`},
+		{Path: "/types?score=9.6", Status: http.StatusBadRequest,
+			Result: `{"error":"score must be <= 9.5"}`},
+		// ParseFloat принимает NaN и Inf, но проверки min и max с ними бессмысленны
+		{Path: "/types?score=NaN", Status: http.StatusBadRequest,
+			Result: `{"error":"score must be float64"}`},
+		{Path: "/types?score=Inf", Status: http.StatusBadRequest,
+			Result: `{"error":"score must be float64"}`},
+		{Path: "/types?score=-Inf", Status: http.StatusBadRequest,
+			Result: `{"error":"score must be float64"}`},
+		{Path: "/types?score=%2Binfinity", Status: http.StatusBadRequest,
+			Result: `{"error":"score must be float64"}`},
+	})
+}
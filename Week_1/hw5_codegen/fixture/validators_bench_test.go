@@ -0,0 +1,102 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"testing"
+
+	apigen "github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/apigen/runtime"
+)
+
+// Сравнение сгенерированной проверки float64 с прежней версией, которая разбирала min/max
+// на каждый запрос и сравнивала value < min, поэтому пропускала NaN
+
+func BenchmarkValidateAndBuildScoreParams(b *testing.B) {
+	params := url.Values{"score": {"2.5"}}
+	ctx := context.Background()
+
+	b.Run("generated", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := validateAndBuildScoreParams(ctx, nil, params, false); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("legacy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := legacyValidateAndBuildScoreParams(params); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestLegacyValidateAndBuildScoreParams(t *testing.T) {
+	cases := []string{"2.5", "0.5", "9.5", "0.49", "9.51", "-1", "1e1", "5e-1", "", "abc"}
+	ctx := context.Background()
+
+	for _, value := range cases {
+		params := url.Values{"score": {value}}
+		res, err := validateAndBuildScoreParams(ctx, nil, params, false)
+		legacyRes, legacyErr := legacyValidateAndBuildScoreParams(params)
+		if fmt.Sprint(res, err) != fmt.Sprint(legacyRes, legacyErr) {
+			t.Errorf("%q: results not match\nGot: %v %v\nExpected: %v %v", value, res, err, legacyRes, legacyErr)
+		}
+	}
+
+	// прежняя версия пропускала NaN, сгенерированная - нет
+	for _, value := range []string{"NaN", "Inf", "-Inf"} {
+		params := url.Values{"score": {value}}
+		expected := apigen.FieldError{Param: "score", Rule: "type", Message: "score must be float64"}
+		if _, err := validateAndBuildScoreParams(ctx, nil, params, false); err != expected {
+			t.Errorf("%q: expected %v, got %v", value, expected, err)
+		}
+	}
+	if _, err := legacyValidateAndBuildScoreParams(url.Values{"score": {"NaN"}}); err != nil {
+		t.Errorf("legacy version is expected to accept NaN, got %v", err)
+	}
+}
+
+func legacyValidateAndBuildScoreParams(params url.Values) (*ScoreParams, error) {
+	paramValue := params.Get("score")
+	if paramValue == "" {
+		return nil, apigen.FieldError{Param: "score", Rule: "required", Message: "score must me not empty"}
+	}
+	value, err := strconv.ParseFloat(paramValue, 64)
+	if err != nil {
+		return nil, apigen.FieldError{Param: "score", Rule: "type", Message: "score must be float64"}
+	}
+	if err = legacyValidateMinMaxFloat(value, "score", "0.5", "9.5"); err != nil {
+		return nil, err
+	}
+	return &ScoreParams{Score: value}, nil
+}
+
+func legacyValidateMinMaxFloat(value float64, valueName, min, max string) error {
+	if min != "" {
+		minFloat, err := strconv.ParseFloat(min, 64)
+		if err != nil {
+			return err
+		}
+		if value < minFloat {
+			return apigen.FieldError{Param: valueName, Rule: "min", Message: valueName + " must be >= " + min}
+		}
+	}
+
+	if max != "" {
+		maxFloat, err := strconv.ParseFloat(max, 64)
+		if err != nil {
+			return err
+		}
+		if value > maxFloat {
+			return apigen.FieldError{Param: valueName, Rule: "max", Message: valueName + " must be <= " + max}
+		}
+	}
+
+	return nil
+}
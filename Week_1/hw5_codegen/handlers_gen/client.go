@@ -0,0 +1,537 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/printer"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+var clientTpl = template.Must(template.New("clientTpl").Parse(`
+package {{.Package}}
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+{{- range .Imports}}
+	{{if .Named}}{{.Alias}} {{end}}"{{.Path}}"
+{{- end}}
+)
+
+// auto-generated file: do not edit!
+
+// Ошибка, которую вернул сервер: HTTP-статус и текст из поля "error" (для application/problem+json - из "detail").
+// Для методов с "errors": "all" в Errors - ошибки всех параметров
+type ApiError struct {
+	HTTPStatus int
+	Err        error
+	Errors     []FieldError
+	// Заголовок X-Request-ID ответа
+	RequestID string
+}
+
+func (ae ApiError) Error() string {
+	return ae.Err.Error()
+}
+
+type FieldError struct {
+	Param   string ` + "`json:\"param\"`" + `
+	Rule    string ` + "`json:\"rule\"`" + `
+	Message string ` + "`json:\"message\"`" + `
+}
+
+type httpResult struct {
+	Error    string          ` + "`json:\"error\"`" + `
+	Errors   []FieldError    ` + "`json:\"errors\"`" + `
+	Response json.RawMessage ` + "`json:\"response\"`" + `
+	// Поля ошибки application/problem+json
+	Title  string ` + "`json:\"title\"`" + `
+	Detail string ` + "`json:\"detail\"`" + `
+}
+
+func (result httpResult) message() string {
+	switch {
+	case result.Error != "":
+		return result.Error
+	case result.Detail != "":
+		return result.Detail
+	default:
+		return result.Title
+	}
+}
+
+// Отправляет запрос и раскладывает ответ в res: при envelope - поле "response" из {"error": ..., "response": ...},
+// иначе всё тело
+func doRequest(ctx context.Context, client *http.Client, authToken, method, endpoint string, params url.Values, res interface{}, envelope bool) error {
+	var req *http.Request
+	var err error
+	if method == http.MethodGet {
+		if len(params) > 0 {
+			endpoint += "?" + params.Encode()
+		}
+		req, err = http.NewRequestWithContext(ctx, method, endpoint, nil)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, method, endpoint, strings.NewReader(params.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if authToken != "" {
+		req.Header.Set("X-Auth", authToken)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusOK && !envelope {
+		if res == nil {
+			return nil
+		}
+		return json.Unmarshal(body, res)
+	}
+
+	result := httpResult{}
+	if err = json.Unmarshal(body, &result); err != nil {
+		return ApiError{HTTPStatus: resp.StatusCode, Err: fmt.Errorf("bad response: %s", resp.Status), RequestID: resp.Header.Get("X-Request-ID")}
+	}
+	if resp.StatusCode != http.StatusOK || result.Error != "" {
+		return ApiError{HTTPStatus: resp.StatusCode, Err: fmt.Errorf("%s", result.message()), Errors: result.Errors, RequestID: resp.Header.Get("X-Request-ID")}
+	}
+
+	if res == nil || len(result.Response) == 0 {
+		return nil
+	}
+	return json.Unmarshal(result.Response, res)
+}
+{{range .Types}}
+{{.}}
+{{end}}
+{{- range .Handlers}}
+{{$handler := .}}
+type {{.Name}}Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// Значение заголовка X-Auth, пустое - заголовок не отправляется
+	AuthToken string
+}
+
+func New{{.Name}}Client(baseURL, authToken string) *{{.Name}}Client {
+	return &{{.Name}}Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		AuthToken:  authToken,
+	}
+}
+{{range .Methods}}
+func (c *{{$handler.Name}}Client) {{.Name}}(ctx context.Context{{range .Args}}, {{.Var}} {{.Type}}{{end}}) {{if .Result}}({{.Result}}, error){{else}}error{{end}} {
+	params := url.Values{}
+{{- range .Params}}
+{{- if .IsSlice}}
+	{{if .Cond}}if {{.Cond}} { {{end}}for _, v := range {{.Expr}} {
+		params.Add({{printf "%q" .Name}}, {{.Value}})
+	}{{if .Cond}} }{{end}}
+{{- else}}
+	{{if .Cond}}if {{.Cond}} { {{end}}params.Set({{printf "%q" .Name}}, {{.Value}}){{if .Cond}} }{{end}}
+{{- end}}
+{{- end}}
+{{if .Result}}
+	var res {{.Result}}
+	err := doRequest(ctx, c.HTTPClient, c.AuthToken, {{printf "%q" .HttpMethod}}, c.BaseURL+{{.Path}}, params, &res, {{$handler.Envelope}})
+	return res, err
+{{- else}}
+	return doRequest(ctx, c.HTTPClient, c.AuthToken, {{printf "%q" .HttpMethod}}, c.BaseURL+{{.Path}}, params, nil, {{$handler.Envelope}})
+{{- end}}
+}
+{{end}}
+{{- end}}
+`))
+
+// Имена, которые объявляет сам клиент: типы пакета с такими именами скопировать нельзя
+var clientReservedNames = map[string]bool{
+	"ApiError":   true,
+	"FieldError": true,
+	"httpResult": true,
+	"doRequest":  true,
+}
+
+type clientData struct {
+	Package  string
+	Imports  []packageImport
+	Types    []string
+	Handlers []*clientHandler
+}
+
+type clientHandler struct {
+	Name    string
+	Methods []*clientMethod
+	// Ответы в обёртке {"error": "", "response": ...}
+	Envelope bool
+}
+
+type clientMethod struct {
+	Name       string
+	HttpMethod string
+	// Выражение Go, из которого получается путь запроса
+	Path   string
+	Args   []*clientArg
+	Result string
+	Params []*clientParam
+}
+
+type clientArg struct {
+	Var  string
+	Type string
+}
+
+// Параметр запроса: params.Set(Name, Value), для слайсов Value считается для каждого v из Expr
+type clientParam struct {
+	Name    string
+	Cond    string
+	Expr    string
+	Value   string
+	IsSlice bool
+}
+
+// Типы пакета, которые нужно объявить в клиенте
+type clientTypes struct {
+	ctx      *parseContext
+	imports  packageImports
+	declared map[string]*ast.TypeSpec
+	files    map[string]*sourceFile
+	copied   map[string]bool
+	order    []string
+}
+
+// Пишет в директорию dir пакет с клиентом ко всем структурам обработчиков
+func generateClient(data *generatorData, dir string) error {
+	ctx := data.ctx
+	res := &clientData{
+		Package:  clientPackageName(dir),
+		Handlers: make([]*clientHandler, 0, len(*data.Handlers)),
+	}
+
+	ct := &clientTypes{
+		ctx:      ctx,
+		imports:  packageImports{},
+		declared: make(map[string]*ast.TypeSpec),
+		files:    make(map[string]*sourceFile),
+		copied:   make(map[string]bool),
+	}
+	for _, file := range *ctx.pkg.Files {
+		for _, decl := range file.Ast.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+					ct.declared[typeSpec.Name.Name] = typeSpec
+					ct.files[typeSpec.Name.Name] = file
+				}
+			}
+		}
+	}
+
+	for _, handler := range data.Handlers.sorted() {
+		if clientReservedNames[handler.Name+"Client"] || ct.declared[handler.Name+"Client"] != nil {
+			return fmt.Errorf("client: name %sClient is already used", handler.Name)
+		}
+
+		h := &clientHandler{
+			Name:     handler.Name,
+			Methods:  make([]*clientMethod, 0, len(*handler.Methods)),
+			Envelope: handler.Specs.Envelope(),
+		}
+		for _, method := range handler.Methods.sorted() {
+			m, err := ct.method(method)
+			if err != nil {
+				return fmt.Errorf("client: %s.%s: %v", handler.Name, method.Name, err)
+			}
+			h.Methods = append(h.Methods, m)
+		}
+		res.Handlers = append(res.Handlers, h)
+	}
+
+	for _, name := range ct.order {
+		var out bytes.Buffer
+		if err := printer.Fprint(&out, ctx.loader.fSet, ct.declared[name]); err != nil {
+			return err
+		}
+		res.Types = append(res.Types, "type "+out.String())
+	}
+	res.Imports = ct.imports.sorted()
+
+	var out bytes.Buffer
+	if err := clientTpl.Execute(&out, res); err != nil {
+		return err
+	}
+
+	formattedCode, err := format.Source(out.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return writeFile(filepath.Join(dir, "client.go"), formattedCode)
+}
+
+// Имя пакета клиента - последний элемент пути без символов, недопустимых в идентификаторе
+func clientPackageName(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, filepath.Base(abs))
+
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "client" + name
+	}
+	return name
+}
+
+func (ct *clientTypes) method(method *handlerMethod) (*clientMethod, error) {
+	res := &clientMethod{
+		Name:       method.Name,
+		HttpMethod: method.Specs.Method.First(),
+		Args:       make([]*clientArg, 0, len(method.Params)),
+		Params:     make([]*clientParam, 0),
+	}
+
+	// Выражения для параметров из пути запроса
+	pathValues := make(map[string]string)
+	if method.Route != nil {
+		for _, routeParam := range method.Route.Params {
+			pathValues[routeParam.Name] = ""
+		}
+	}
+
+	for i, s := range method.Params {
+		arg := &clientArg{Var: "in", Type: s.Name}
+		if len(method.Params) > 1 {
+			arg.Var = "in" + strconv.Itoa(i)
+		}
+		if err := ct.addStruct(s); err != nil {
+			return nil, err
+		}
+		res.Args = append(res.Args, arg)
+
+		for _, p := range *s.Params {
+			param, err := ct.param(arg.Var, s, p)
+			if err != nil {
+				return nil, err
+			}
+
+			if value, ok := pathValues[p.ParamName]; ok {
+				if value == "" && param.Cond == "" && !param.IsSlice {
+					pathValues[p.ParamName] = param.Value
+				}
+				continue
+			}
+			res.Params = append(res.Params, param)
+		}
+	}
+
+	for name, value := range pathValues {
+		if value == "" {
+			return nil, fmt.Errorf("url param %s must be bound to a plain field", name)
+		}
+	}
+	res.Path = clientPath(method.Specs.Url, pathValues)
+
+	if method.Result != nil {
+		if err := ct.addType(method.Result, method.File, ct.ctx.pkg); err != nil {
+			return nil, err
+		}
+		res.Result = types.ExprString(method.Result)
+	}
+
+	return res, nil
+}
+
+// Как параметр p структуры s достаётся из аргумента arg
+func (ct *clientTypes) param(arg string, s *dataStruct, p *structParam) (*clientParam, error) {
+	res := &clientParam{
+		Name:    p.ParamName,
+		Expr:    arg + "." + p.Path,
+		IsSlice: p.IsSlice,
+	}
+
+	// Вложенные структуры по указателю могут быть nil
+	conds := make([]string, 0)
+	for _, alloc := range s.Allocs {
+		if strings.HasPrefix(p.Path, alloc.Path+".") {
+			conds = append(conds, arg+"."+alloc.Path+" != nil")
+		}
+	}
+
+	value := res.Expr
+	switch {
+	case p.IsSlice:
+		value = "v"
+	case p.IsPointer:
+		conds = append(conds, res.Expr+" != nil")
+		value = "*" + res.Expr
+	}
+	res.Cond = strings.Join(conds, " && ")
+	res.Value = p.Type.Format(value)
+
+	if importPath := p.Type.FormatImport(); importPath != "" {
+		if err := ct.imports.add(importPath, importPath); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// Собирает путь запроса, подставляя значения параметров вместо {name}
+func clientPath(url string, values map[string]string) string {
+	parts := make([]string, 0)
+	rest := url
+	for {
+		open := strings.Index(rest, "{")
+		if open == -1 {
+			break
+		}
+		closing := strings.Index(rest, "}")
+		name := strings.SplitN(rest[open+1:closing], ":", 2)[0]
+
+		if open > 0 {
+			parts = append(parts, strconv.Quote(rest[:open]))
+		}
+		parts = append(parts, "url.PathEscape("+values[name]+")")
+		rest = rest[closing+1:]
+	}
+	if rest != "" || len(parts) == 0 {
+		parts = append(parts, strconv.Quote(rest))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// Добавляет в клиент структуру параметров: свою - копией, из другого пакета - импортом
+func (ct *clientTypes) addStruct(s *dataStruct) error {
+	if s.Package.Dir == ct.ctx.pkg.Dir {
+		return ct.addType(ast.NewIdent(s.Name), s.File, s.Package)
+	}
+	alias := strings.SplitN(s.Name, ".", 2)[0]
+	return ct.addPackage(alias, s.Package)
+}
+
+func (ct *clientTypes) addPackage(alias string, pkg *sourcePackage) error {
+	if pkg.Name == "main" {
+		return fmt.Errorf("package %s can not be imported", pkg.ImportPath)
+	}
+	return ct.imports.add(alias, pkg.ImportPath)
+}
+
+// Обходит выражение типа и копирует в клиент все упомянутые в нём типы пакета
+func (ct *clientTypes) addType(expr ast.Expr, file *sourceFile, pkg *sourcePackage) error {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if types.Universe.Lookup(t.Name) != nil || ct.copied[t.Name] {
+			return nil
+		}
+		spec, ok := ct.declared[t.Name]
+		if !ok {
+			return fmt.Errorf("unknown type %s", t.Name)
+		}
+		if clientReservedNames[t.Name] {
+			return fmt.Errorf("type %s conflicts with client declarations", t.Name)
+		}
+		ct.copied[t.Name] = true
+		ct.order = append(ct.order, t.Name)
+		return ct.addType(spec.Type, ct.files[t.Name], pkg)
+
+	case *ast.SelectorExpr:
+		pkgIdent, ok := t.X.(*ast.Ident)
+		if !ok {
+			return fmt.Errorf("unsupported type: %s", types.ExprString(expr))
+		}
+		importPath, err := ct.ctx.loader.resolveImport(file, pkgIdent.Name, pkg.Dir)
+		if err != nil {
+			return err
+		}
+		imported, err := ct.ctx.loader.loadImport(importPath, pkg.Dir)
+		if err != nil {
+			return err
+		}
+		return ct.addPackage(pkgIdent.Name, imported)
+
+	case *ast.StarExpr:
+		return ct.addType(t.X, file, pkg)
+	case *ast.ParenExpr:
+		return ct.addType(t.X, file, pkg)
+	case *ast.Ellipsis:
+		return ct.addType(t.Elt, file, pkg)
+	case *ast.ArrayType:
+		return ct.addType(t.Elt, file, pkg)
+	case *ast.ChanType:
+		return ct.addType(t.Value, file, pkg)
+
+	case *ast.MapType:
+		if err := ct.addType(t.Key, file, pkg); err != nil {
+			return err
+		}
+		return ct.addType(t.Value, file, pkg)
+
+	case *ast.StructType:
+		return ct.addFields(t.Fields, file, pkg)
+	case *ast.InterfaceType:
+		return ct.addFields(t.Methods, file, pkg)
+
+	case *ast.FuncType:
+		if err := ct.addFields(t.Params, file, pkg); err != nil {
+			return err
+		}
+		return ct.addFields(t.Results, file, pkg)
+	}
+
+	return fmt.Errorf("unsupported type: %s", types.ExprString(expr))
+}
+
+func (ct *clientTypes) addFields(fields *ast.FieldList, file *sourceFile, pkg *sourcePackage) error {
+	if fields == nil {
+		return nil
+	}
+	for _, field := range fields.List {
+		if err := ct.addType(field.Type, file, pkg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,176 +3,481 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"go/ast"
 	"go/format"
 	"go/parser"
-	"go/printer"
 	"go/token"
 	"io"
 	"log"
+	"net/http"
 	"os"
+	"path"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 )
 
 // код писать тут
 
+var importsTpl = template.Must(template.New("importsTpl").Parse(`
+import (
+	{{- range .Imports}}
+	{{if .Named}}{{.Alias}} {{end}}"{{.Path}}"
+	{{- end}}
+)
+
+// auto-generated file: do not edit!
+
+// Не скомпилируется с версией apigen/runtime, в которой нет API этого файла
+const _ = apigen.APIVersion{{.Version}}
+`))
+
 var serveHTTPMethodTpl = template.Must(template.New("serveHTTPMethodTpl").Parse(`
 func (h *{{.Name}}) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch r.URL.Path {
 	{{- range $key, $value := .Methods -}}
+	{{- if not $value.Route}}
 	case "{{$value.Specs.Url}}":
-		h.wrapper{{ $value.Name }}(w, r)
-	{{end -}}
+		h.wrapper{{ $value.Name }}(w, r, nil)
+	{{- end}}
+	{{- end}}
 	default:
-		w.WriteHeader(http.StatusNotFound)
-		writeResponse(w, marshal(httpResult{Error: "unknown method"}))
+		{{- range $value := .RouteMethods}}
+		if m := {{$value.Route.Var}}.FindStringSubmatch(r.URL.Path); m != nil {
+			h.wrapper{{$value.Name}}(w, r, url.Values{
+				{{- range $value.Route.Params}}
+				"{{.Name}}": {m[{{.Index}}]},
+				{{- end}}
+			})
+			return
+		}
+		{{- end}}
+		h.getEncoder().EncodeError(w, r, http.StatusNotFound, errors.New("unknown method"))
 	}
 }
+
+{{- range $key, $value := .Methods}}
+{{- if $value.Route}}
+
+var {{$value.Route.Var}} = regexp.MustCompile({{printf "%q" $value.Route.Pattern}})
+{{- end}}
+{{- end}}
+`))
+
+var handlerGettersTpl = template.Must(template.New("handlerGettersTpl").Parse(`
+{{- range .Getters}}
+func (h *{{.Handler}}) get{{.Interface}}() apigen.{{.Interface}} {
+	{{- if .Field}}
+	if h.{{.Field}} != nil {
+		return h.{{.Field}}
+	}
+	{{- end}}
+	if v, ok := interface{}(h).(apigen.{{.Interface}}); ok {
+		return v
+	}
+	return {{.Default}}
+}
+{{end}}
 `))
 
 var handlerMethodTpl = template.Must(template.New("handlerMethodTpl").Parse(`
-func (h *{{.ObjectName}}) wrapper{{.Name}}(w http.ResponseWriter, r *http.Request) {
+func (h *{{.ObjectName}}) wrapper{{.Name}}(w http.ResponseWriter, r *http.Request, pathParams url.Values) {
+	metrics := h.getMetrics()
+	sw := &apigen.StatusWriter{ResponseWriter: w}
+	w = sw
+	defer func(start time.Time) {
+		metrics.ObserveRequest({{printf "%q" .Specs.Url}}, sw.Status(), time.Since(start))
+	}(time.Now())
+
+	r = apigen.PropagateRequestID(w, r)
 	ctx := r.Context()
-	{{if ne .Specs.Method ""}}
-	if r.Method != "{{.Specs.Method}}" {
-		w.WriteHeader(http.StatusNotAcceptable)
-		writeResponse(w, marshal(httpResult{Error: "bad method"}))
+	encoder := h.getEncoder()
+	defer func() {
+		if p := recover(); p != nil {
+			apigen.RecoverPanic(w, r, encoder, h.getLogger(), "{{.ObjectName}}.{{.Name}}", p)
+		}
+	}()
+	{{if .Specs.Method.AutoOptions}}
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", {{printf "%q" .Specs.Method.Allow}})
+		w.WriteHeader(http.StatusNoContent)
 		return
 	}
 	{{end}}
+	{{- if .Specs.Method}}
+	switch r.Method {
+	case {{range $i, $m := .Specs.Method.Allowed}}{{if $i}}, {{end}}{{printf "%q" $m}}{{end}}:
+	default:
+		w.Header().Set("Allow", {{printf "%q" .Specs.Method.Allow}})
+		{{- if .Handler.Specs.StrictMethods}}
+		encoder.EncodeError(w, r, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		{{- else}}
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("bad method"))
+		{{- end}}
+		return
+	}
+	{{end}}
+
+	if !apigen.Acceptable(encoder, r) {
+		encoder.EncodeError(w, r, http.StatusNotAcceptable, errors.New("not acceptable"))
+		return
+	}
+	{{- with .Specs.RateLimit}}{{if not .PerAuth}}
+	{{template "rateLimit" $}}
+	{{- end}}{{end}}
 
 	{{if .Specs.Auth}}
-	if r.Header.Get("X-Auth") != "100500" {
-		w.WriteHeader(http.StatusForbidden)
-		writeResponse(w, marshal(httpResult{Error: "unauthorized"}))
+	principal, err := h.getAuthenticator().Authenticate(r)
+	if err != nil {
+		status := http.StatusForbidden
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
+		}
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	ctx = apigen.ContextWithPrincipal(ctx, principal)
+	{{if .Specs.Roles}}
+	if !principal.HasAnyRole({{range $i, $role := .Specs.Roles}}{{if $i}}, {{end}}{{printf "%q" $role}}{{end}}) {
+		encoder.EncodeError(w, r, http.StatusForbidden, errors.New("forbidden"))
 		return
 	}
 	{{end}}
+	{{- with .Specs.RateLimit}}{{if .PerAuth}}
+	{{template "rateLimit" $}}
+	{{end}}{{end}}
+	{{end}}
+	{{- with .Specs.TimeoutExpr}}
+
+	// timeout: {{$.Specs.Timeout}}
+	ctx, cancel := context.WithTimeout(ctx, {{.}})
+	defer cancel()
+	{{- end}}
+	r = r.WithContext(ctx)
+
+	{{- if .Specs.Middleware}}
 
-	{{- range $i, $pType := .ParamTypes}}
-	p{{$i}}, err := validateAndBuild{{$pType}}(r)
+	var next http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.handle{{.Name}}(w, r, encoder, pathParams)
+	})
+	{{- range .Specs.MiddlewareChain}}
+	next = {{.}}(next)
+	{{- end}}
+	next.ServeHTTP(w, r)
+	{{- else}}
+	h.handle{{.Name}}(w, r, encoder, pathParams)
+	{{- end}}
+}
+
+// Проверка параметров и вызов метода, вокруг них работают middleware
+func (h *{{.ObjectName}}) handle{{.Name}}(w http.ResponseWriter, r *http.Request, encoder apigen.Encoder, pathParams url.Values) {
+	ctx := r.Context()
+	params, err := apigen.ReadParams(r)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		writeResponse(w, marshal(httpResult{Error: err.Error()}))
+		status := http.StatusBadRequest
+		if err == apigen.ErrBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		h.getMetrics().ObserveValidationFailure({{printf "%q" .Specs.Url}})
+		encoder.EncodeError(w, r, status, err)
+		return
+	}
+	params = apigen.MergeParams(params, pathParams)
+
+	{{- if .Specs.CollectErrors}}
+
+	var fieldErrors apigen.ValidationErrors
+	{{- range $i, $p := .Params}}
+	p{{$i}}, err := validateAndBuild{{$p.Ident}}(ctx, h, params, true)
+	if err != nil {
+		fieldErrors = append(fieldErrors, err.(apigen.ValidationErrors)...)
+	}
+	{{- end}}
+	if len(fieldErrors) > 0 {
+		h.getMetrics().ObserveValidationFailure({{printf "%q" .Specs.Url}})
+		encoder.EncodeError(w, r, http.StatusBadRequest, fieldErrors)
+		return
+	}
+	{{else}}
+	{{- range $i, $p := .Params}}
+	p{{$i}}, err := validateAndBuild{{$p.Ident}}(ctx, h, params, false)
+	if err != nil {
+		h.getMetrics().ObserveValidationFailure({{printf "%q" $.Specs.Url}})
+		encoder.EncodeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 	{{end}}
+	{{- end}}
+	{{- if .Specs.Cache}}{{if .Specs.Cache.Memory}}
+	cacheKey, cacheable := apigen.CacheKey({{printf "%q" .Endpoint}}, r{{range $i, $p := .Params}}, p{{$i}}{{end}})
+	if cacheable {
+		if cached, ok := h.getCache().Get(cacheKey); ok {
+			apigen.WriteWithETag(w, r, encoder, cached, {{.Specs.Cache.TTLExpr}}, {{.Specs.Auth}})
+			return
+		}
+	}
+	{{end}}{{end}}
 	res, err := h.{{.Name}}(
 		ctx,
-		{{- range $i, $pType := .ParamTypes}}
+		{{- range $i, $p := .Params}}
 		*p{{$i}},
 		{{end}}
 	)
 
 	if err != nil {
-		apiErr, ok := err.(ApiError)
-		if ok {
-			w.WriteHeader(apiErr.HTTPStatus)
+		status := http.StatusInternalServerError
+		if apiErr, ok := err.(ApiError); ok {
+			status = apiErr.HTTPStatus
 		} else {
-			w.WriteHeader(http.StatusInternalServerError)
+			{{- if .Specs.Timeout}}
+			if errors.Is(err, context.DeadlineExceeded) {
+				status = http.StatusGatewayTimeout
+			}
+			{{- end}}
+			h.getLogger().LogError(r, apigen.ErrorEvent{Handler: "{{.ObjectName}}.{{.Name}}", Status: status, Err: err})
 		}
-		writeResponse(w, marshal(httpResult{Error: err.Error()}))
+		encoder.EncodeError(w, r, status, err)
 		return
 	}
-
-	w.WriteHeader(http.StatusOK)
-	writeResponse(w, marshal(httpResult{Response: res}))
+	{{with .Specs.Cache}}
+	{{- if .Memory}}
+	if cacheable {
+		h.getCache().Set(cacheKey, res, {{.TTLExpr}})
+	}
+	{{- end}}
+	// cache: {{.TTL}}
+	apigen.WriteWithETag(w, r, encoder, res, {{.TTLExpr}}, {{$.Specs.Auth}})
+	{{- else}}
+	encoder.EncodeResponse(w, r, res)
+	{{- end}}
 }
+
+{{- define "rateLimit"}}
+	// ratelimit: {{.Specs.RateLimit.Rate}} per {{.Specs.RateLimit.Key}}
+	if !apigen.AllowRequest(h.getLimiter(), w, apigen.RateLimitKey({{printf "%q" .Endpoint}}, r, {{if .Specs.RateLimit.PerAuth}}principal{{else}}nil{{end}}), {{.Specs.RateLimit.RateExpr}}) {
+		encoder.EncodeError(w, r, http.StatusTooManyRequests, errors.New("too many requests"))
+		return
+	}
+{{- end}}
 `))
 
 var validateAndBuildDataStructTpl = template.Must(template.New("validateAndBuildDataStructTpl").Parse(`
-func validateAndBuild{{.Name}}(r *http.Request) (*{{.Name}}, error) {
+{{- range .Params}}
+{{- if .PatternVar}}
+var {{.PatternVar}} = regexp.MustCompile({{printf "%q" .Validator.Pattern}})
+{{- end}}
+{{- end}}
+
+{{- range .Limits}}
+{{if .IsVar}}var {{.Name}} = {{.Value}}{{else}}const {{.Name}} {{.Type}} = {{.Value}}{{end}}
+{{- end}}
+
+// h - обработчик, у которого вызываются методы-проверки validate=...
+func validateAndBuild{{.Ident}}(ctx context.Context, h interface{}, params url.Values, collectAll bool) (*{{.Name}}, error) {
 	res := {{.Name}}{}
-	
-	var paramName string
-	var paramValue string
-	var required bool
-	var defaultValue string
-	var enum []string
+	{{- range .Allocs}}
+	res.{{.Path}} = &{{.Type}}{}
+	{{- end}}
+	{{- if .HasParams}}
 
+	var errs apigen.ValidationErrors
 	var err error
 
-	{{- range $key, $value := .Fields}}
-	{{if ne $value.Validator.ParamName ""}}
-	paramName = "{{$value.Validator.ParamName}}"
-	{{else}}
-	paramName = strings.ToLower("{{$key}}")
-	{{end}}
+	{{- range $value := .Params}}
 
-	paramValue = r.FormValue(paramName)
-	required = {{$value.Validator.Required}}
-	
-	if required && paramValue == "" {
-		return nil, fmt.Errorf(paramName + " must me not empty")
+	err = func() error {
+		{{- if $value.IsSlice}}
+		{{$value.Var}}Values := params[{{printf "%q" $value.ParamName}}]
+		{{- if $value.Validator.Required}}
+		if len({{$value.Var}}Values) == 0 {
+			return {{$value.FieldError "required" " must me not empty"}}
+		}
+		{{- end}}
+		{{- if $value.Validator.Default}}
+		if len({{$value.Var}}Values) == 0 {
+			{{$value.Var}}Values = []string{ {{- printf "%q" $value.Validator.Default -}} }
+		}
+		{{- end}}
+
+		res.{{$value.Path}} = make([]{{$value.Type.GoType}}, 0, len({{$value.Var}}Values))
+		for _, paramValue := range {{$value.Var}}Values {
+			{{- template "validateValue" $value}}
+			res.{{$value.Path}} = append(res.{{$value.Path}}, {{$value.Var}}Val)
+		}
+		{{- else}}
+		paramValue := params.Get({{printf "%q" $value.ParamName}})
+		{{- if $value.Validator.Required}}
+		if paramValue == "" {
+			return {{$value.FieldError "required" " must me not empty"}}
+		}
+		{{- end}}
+		{{- if $value.Validator.Default}}
+		if paramValue == "" {
+			paramValue = {{printf "%q" $value.Validator.Default}}
+		}
+		{{- end}}
+		{{- if $value.IsPointer}}
+
+		if paramValue != "" {
+			{{- template "validateValue" $value}}
+			res.{{$value.Path}} = &{{$value.Var}}Val
+		}
+		{{- else}}
+		{{- template "validateValue" $value}}
+		res.{{$value.Path}} = {{$value.Var}}Val
+		{{- end}}
+		{{- end}}
+		return nil
+	}()
+	if err != nil {
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
 	}
+	{{- end}}
+
+	{{- range .CrossRules}}
 
-	defaultValue = "{{$value.Validator.Default}}"
-	if paramValue == "" && defaultValue != "" {
-		paramValue = defaultValue
+	if !errs.Has("{{.Param}}") && !errs.Has("{{.Other}}") && {{.Cond}} {
+		err = apigen.FieldError{Param: "{{.Param}}", Rule: "{{.Rule}}", Message: {{printf "%q" .Message}}}
+		if !collectAll {
+			return nil, err
+		}
+		errs = append(errs, err.(apigen.FieldError))
 	}
+	{{- end}}
 
-	enum = make([]string, 0)
-	{{- range $value.Validator.Enum}}
-	enum = append(enum, "{{.}}")
+	if len(errs) > 0 {
+		return nil, errs
+	}
 	{{- end}}
-	if len(enum) > 0 && !contains(enum, paramValue) {
-		return nil, fmt.Errorf(paramName + " must be one of " + printSlice(enum))
+	return &res, nil
+}
+
+{{define "validateValue"}}
+
+	{{if eq .Type.GoType "string"}}
+	{{- .Var}}Val := paramValue
+	{{- else}}
+	{{- .Var}}Val, err := {{.Type.Parse "paramValue"}}
+	if err != nil{{with .Type.Invalid (printf "%sVal" .Var)}} || {{.}}{{end}} {
+		return {{.FieldError "type" .TypeMessage}}
 	}
+	{{- end}}
 
-	{{if eq $value.Type 0}}
-	int{{$key}}Val, err := strconv.Atoi(paramValue)
-	if err != nil {
-		return nil, fmt.Errorf(paramName + " must be int")
+	{{- if .Validator.Enum}}
+
+	switch {{.Var}}Val {
+	case {{.EnumCases}}:
+	default:
+		return {{.FieldError "enum" .EnumMessage}}
 	}
-	if err = validateMinMaxInt(int{{$key}}Val, paramName, "{{$value.Validator.Min}}", "{{$value.Validator.Max}}"); err != nil {
-		return nil, err
+	{{- end}}
+
+	{{- range .Checks}}
+	if {{.Cond}} {
+		return {{.Error}}
 	}
-	res.{{$key}} = int{{$key}}Val
-	{{else}}
-	if err = validateMinMaxStr(paramValue, paramName, "{{$value.Validator.Min}}", "{{$value.Validator.Max}}"); err != nil {
-		return nil, err
+	{{- end}}
+
+	{{- if .Validator.Format}}
+	if err := {{.FormatFunc}}({{.Var}}Val, {{printf "%q" .ParamName}}); err != nil {
+		return err
 	}
-	res.{{$key}} = paramValue
-	{{end}}
-	{{end}}
-	return &res, nil
-}
+	{{- end}}
+
+	{{- with .Hook}}
+	if err := {{.Call}}({{if .WithContext}}ctx, {{end}}{{$.Var}}Val); err != nil {
+		return apigen.FieldError{Param: {{printf "%q" $.ParamName}}, Rule: "validate", Message: err.Error()}
+	}
+	{{- end}}
+{{end}}
 `))
 
+var openApiOutput = flag.String("openapi", "", "write OpenAPI 3 document to this file (one file per handler struct if there are several)")
+var clientOutput = flag.String("client", "", "write typed Go client package to this directory")
+var runtimeImport = flag.String("runtime", defaultRuntimeImport, "import path of apigen/runtime package for generated code")
+
+const defaultRuntimeImport = "github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/apigen/runtime"
+
+// Версия API apigen/runtime, на которую рассчитан сгенерированный код
+const runtimeVersion = 8
+
 func main() {
-	fSet := token.NewFileSet()
-	root, err := parser.ParseFile(fSet, os.Args[1], nil, parser.ParseComments)
-	checkAndLogError(err)
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: codegen [flags] api.go|package_dir api_handlers.go")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	target, output := flag.Arg(0), flag.Arg(1)
 
-	var file *os.File
-	file, err = os.Create(os.Args[2])
+	data, formattedCode, err := generate(target, output)
 	checkAndLogError(err)
 
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil {
-			log.Fatal(closeErr)
-		}
-	}()
+	// Файлы пишем только после успешной генерации, чтобы не оставить пакет с обрезанным файлом
+	checkAndLogError(writeFile(output, formattedCode))
 
-	var out bytes.Buffer
-	fPrintln(&out, `package `+root.Name.Name)
+	if *openApiOutput != "" {
+		checkAndLogError(generateOpenApi(data, *openApiOutput))
+	}
 
-	var handlers *handlerObjects
-	var structs *dataStructs
-	handlers, structs, err = parse(root, fSet)
-	checkAndLogError(err)
+	if *clientOutput != "" {
+		checkAndLogError(generateClient(data, *clientOutput))
+	}
+}
 
-	err = generateCode(handlers, structs, &out)
-	checkAndLogError(err)
+// Разбирает target (файл или директорию пакета) и возвращает код для output, сам output не читается
+func generate(target, output string) (*generatorData, []byte, error) {
+	loader := newPackageLoader(token.NewFileSet())
+
+	pkg, err := loader.loadTarget(target, output)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := parse(pkg, loader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var out bytes.Buffer
+	fPrintln(&out, `package `+pkg.Name)
+
+	if err = generateCode(data, &out); err != nil {
+		return nil, nil, err
+	}
 
 	formattedCode, err := format.Source(out.Bytes())
-	checkAndLogError(err)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, formattedCode, nil
+}
 
-	_, err = file.Write(formattedCode)
-	checkAndLogError(err)
+func writeFile(path string, content []byte) (err error) {
+	var file *os.File
+	if file, err = os.Create(path); err != nil {
+		return err
+	}
+
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+
+	_, err = file.Write(content)
+	return err
 }
 
 func checkAndLogError(err error) {
@@ -181,195 +486,189 @@ func checkAndLogError(err error) {
 	}
 }
 
-func generateCode(handlers *handlerObjects, structs *dataStructs, w io.Writer) error {
-	generateImports(w)
-	generateCommon(w)
-
-	for _, handler := range *handlers {
-		if err := generateHandler(handler, w); err != nil {
+func generateCode(data *generatorData, w io.Writer) error {
+	var body bytes.Buffer
+	for _, handler := range data.Handlers.sorted() {
+		if err := generateHandler(handler, data.target, &body); err != nil {
 			return err
 		}
 	}
 
-	for _, s := range *structs {
-		if err := validateAndBuildDataStructTpl.Execute(w, s); err != nil {
+	for _, s := range data.Structs.sorted() {
+		if err := validateAndBuildDataStructTpl.Execute(&body, s); err != nil {
 			return err
 		}
 	}
 
-	return nil
-}
-
-func generateCommon(w io.Writer) {
-	fPrintln(
-		w,
-		"type httpResult struct {\n\tError    string      `json:\"error\"`\n\tResponse interface{} `json:\"response\"`\n}",
-	)
-
-	fPrintln(w, `
-func marshal(res httpResult) []byte {
-	resMap := make(map[string]interface{})
-	resMap["error"] = res.Error
-	if res.Response != nil {
-		resMap["response"] = res.Response
+	imports, err := usedImports(data.Imports.sorted(), body.Bytes())
+	if err != nil {
+		return err
 	}
-	resultStr, _ := json.Marshal(resMap)
-	return resultStr
-}`)
-
-	fPrintln(w, `
-func writeResponse(w http.ResponseWriter, response []byte) {
-	_, _ = w.Write(response)
-}`)
-
-	fPrintln(w, `
-func contains(arr []string, item string) bool {
-	for _, i := range arr {
-		if item == i {
-			return true
-		}
+	err = importsTpl.Execute(w, struct {
+		Imports []packageImport
+		Version int
+	}{imports, runtimeVersion})
+	if err != nil {
+		return err
 	}
-	return false
-}`)
 
-	fPrintln(w, `
-func printSlice(s []string) string {
-	return "[" + strings.Join(s, ", ") + "]"
-}`)
+	_, err = w.Write(body.Bytes())
+	return err
+}
 
-	fPrintln(w, `
-func validateMinMaxInt(value int, valueName, min, max string) error {
-	if min != "" {
-		minInt, err := strconv.Atoi(min)
-		if err != nil {
-			return err
-		}
-		if value < minInt {
-			return fmt.Errorf(valueName + " must be >= " + min)
-		}
+// Импорты, на которые ссылается body. Пакеты добавляются при разборе всех методов и структур,
+// а в файл для одного файла пакета попадает только часть из них. apigen нужен всегда
+func usedImports(imports []packageImport, body []byte) ([]packageImport, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", append([]byte("package p\n"), body...), 0)
+	if err != nil {
+		return nil, err
 	}
 
-	if max != "" {
-		maxInt, err := strconv.Atoi(max)
-		if err != nil {
-			return err
+	used := map[string]bool{"apigen": true}
+	ast.Inspect(file, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok {
+			if ident, ok := selector.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
 		}
-		if value > maxInt {
-			return fmt.Errorf(valueName + " must be <= " + max)
+		return true
+	})
+
+	res := make([]packageImport, 0, len(imports))
+	for _, i := range imports {
+		if used[i.Alias] {
+			res = append(res, i)
 		}
 	}
+	return res, nil
+}
 
-	return nil
-}`)
-
-	fPrintln(w, `
-func validateMinMaxStr(value, valueName, min, max string) error {
-	if min != "" {
-		minInt, err := strconv.Atoi(min)
-		if err != nil {
+// target - файл пакета, для которого генерируется код, пусто - весь пакет
+func generateHandler(handler *handlerObject, target string, w io.Writer) error {
+	if target == "" || handler.OwnerFile() == target {
+		if err := serveHTTPMethodTpl.Execute(w, handler); err != nil {
 			return err
 		}
-		if len(value) < minInt {
-			return fmt.Errorf(valueName + " len must be >= " + min)
+		if err := handlerGettersTpl.Execute(w, handler); err != nil {
+			return err
 		}
 	}
 
-	if max != "" {
-		maxInt, err := strconv.Atoi(max)
-		if err != nil {
-			return err
+	for _, method := range handler.Methods.sorted() {
+		if target != "" && method.File.Path != target {
+			continue
 		}
-		if len(value) > maxInt {
-			return fmt.Errorf(valueName + " len must be <= " + max)
+		if err := handlerMethodTpl.Execute(w, method); err != nil {
+			return err
 		}
 	}
 
 	return nil
-}`)
 }
 
-func generateImports(w io.Writer) {
-	fPrintln(w, `
-import (
-	"encoding/json"
-	"net/http"
-	"strconv"
-	"strings"
-	"fmt"
-	"strconv"
-)
-
-// auto-generated file: do not edit!
-`,
-	)
-}
+func parse(pkg *sourcePackage, loader *packageLoader) (*generatorData, error) {
+	handlers := handlerObjects(make(map[string]*handlerObject))
+	ctx := &parseContext{
+		loader:  loader,
+		pkg:     pkg,
+		structs: make(map[string]*dataStructs),
+		data: &generatorData{
+			Handlers: &handlers,
+			Structs:  &dataStructs{},
+			Imports:  &packageImports{},
+		},
+	}
+	ctx.data.ctx = ctx
+	if err := ctx.data.Imports.add("apigen", *runtimeImport); err != nil {
+		return nil, err
+	}
 
-func generateHandler(handler *handlerObject, w io.Writer) error {
-	err := serveHTTPMethodTpl.Execute(w, handler)
-	if err != nil {
-		return err
+	// методы разбираются во всех файлах: проверки структур параметров должны подходить всем обработчикам
+	for _, file := range *pkg.Files {
+		for _, node := range file.Ast.Decls {
+			if funcNode, isFuncNode := node.(*ast.FuncDecl); isFuncNode {
+				if err := tryParseHandler(funcNode, file, ctx); err != nil {
+					return nil, err
+				}
+			}
+		}
 	}
 
-	for _, method := range *handler.Methods {
-		if err = handlerMethodTpl.Execute(w, method); err != nil {
-			return err
+	for _, handler := range ctx.data.Handlers.sorted() {
+		if err := handler.checkRoutes(); err != nil {
+			return nil, fmt.Errorf("%s: %v", handler.Name, err)
 		}
 	}
 
-	return nil
+	if pkg.Target != "" {
+		ctx.data.keepTarget(pkg.Target)
+	}
+	return ctx.data, nil
 }
 
-func parse(root *ast.File, fSet *token.FileSet) (*handlerObjects, *dataStructs, error) {
-	handlers := handlerObjects(make(map[string]*handlerObject))
-	structs := dataStructs(make(map[string]*dataStruct))
-
-	for _, node := range root.Decls {
-		funcNode, isFuncNode := node.(*ast.FuncDecl)
-		genNode, isGenNode := node.(*ast.GenDecl)
-
-		if isFuncNode {
-			if err := tryParseHandler(funcNode, &handlers, fSet); err != nil {
-				return nil, nil, err
+// Оставляет то, что генерируется для одного файла пакета: обработчики с методами из этого файла
+// и validateAndBuild структур, которыми он владеет. Владелец структуры - первый по имени файл с методами,
+// которые её принимают, поэтому при запуске для каждого файла отдельно общая структура попадает ровно
+// в один результат. Так же ServeHTTP и get* обработчика попадают только в OwnerFile
+func (data *generatorData) keepTarget(target string) {
+	data.target = target
+	owners := make(map[*dataStruct]string)
+	for _, handler := range *data.Handlers {
+		for _, method := range *handler.Methods {
+			for _, s := range method.Params {
+				if owner, ok := owners[s]; !ok || method.File.Path < owner {
+					owners[s] = method.File.Path
+				}
 			}
-			continue
 		}
+	}
 
-		if isGenNode {
-			if err := tryParseDataStruct(genNode, &structs); err != nil {
-				return nil, nil, err
-			}
-			continue
+	for name, handler := range *data.Handlers {
+		if !handler.hasMethodsIn(target) {
+			delete(*data.Handlers, name)
 		}
 	}
 
-	return &handlers, &structs, nil
+	for name, s := range *data.Structs {
+		if owners[s] != target {
+			delete(*data.Structs, name)
+		}
+	}
 }
 
-func tryParseHandler(funcNode *ast.FuncDecl, handlers *handlerObjects, fSet *token.FileSet) error {
-	if funcNode.Doc == nil || len(funcNode.Doc.List) == 0 {
-		return nil
-	}
+// Собирает структуры со всех файлов пакета
+func parseDataStructs(pkg *sourcePackage) (*dataStructs, error) {
+	structs := dataStructs(make(map[string]*dataStruct))
 
-	apiGenJsonStr := ""
-	for _, comment := range funcNode.Doc.List {
-		if index := strings.Index(comment.Text, "apigen:api "); index != -1 {
-			index += 11
-			if index+2 >= len(comment.Text) {
-				return fmt.Errorf("invalid handler func comment: %s", comment.Text)
+	for _, file := range *pkg.Files {
+		for _, node := range file.Ast.Decls {
+			if genNode, isGenNode := node.(*ast.GenDecl); isGenNode {
+				if err := tryParseDataStruct(genNode, file, pkg, &structs); err != nil {
+					return nil, err
+				}
 			}
-			apiGenJsonStr = comment.Text[index:]
-			break
 		}
 	}
 
+	return &structs, nil
+}
+
+func tryParseHandler(funcNode *ast.FuncDecl, file *sourceFile, ctx *parseContext) error {
+	apiGenJsonStr, err := findAnnotation(funcNode.Doc, "apigen:api ")
+	if err != nil {
+		return fmt.Errorf("invalid handler func comment: %v", err)
+	}
 	if apiGenJsonStr == "" {
 		return nil
 	}
 
 	handlerMethodSpecs := &HandlerMethodSpecs{}
-	err := json.Unmarshal([]byte(apiGenJsonStr), handlerMethodSpecs)
+	err = json.Unmarshal([]byte(apiGenJsonStr), handlerMethodSpecs)
 	if err != nil {
-		return err
+		return fmt.Errorf("%s: %v", funcNode.Name.Name, err)
+	}
+	if err = handlerMethodSpecs.check(); err != nil {
+		return fmt.Errorf("%s: %v", funcNode.Name.Name, err)
 	}
 
 	// Объект метода
@@ -388,35 +687,103 @@ func tryParseHandler(funcNode *ast.FuncDecl, handlers *handlerObjects, fSet *tok
 		return nil
 	}
 
+	handlers := ctx.data.Handlers
 	if _, exists := (*handlers)[objectName]; !exists {
 		methods := handlerMethods(make(map[string]*handlerMethod))
-		(*handlers)[objectName] = &handlerObject{objectName, &methods}
+		authField, err := ctx.findAuthenticatorField(objectName)
+		if err != nil {
+			return err
+		}
+		specs, err := ctx.findHandlerSpecs(objectName)
+		if err != nil {
+			return err
+		}
+		(*handlers)[objectName] = &handlerObject{objectName, &methods, authField, specs}
+		for _, importPath := range []string{"errors", "net/http", "net/url", "time"} {
+			if err = ctx.data.Imports.add(path.Base(importPath), importPath); err != nil {
+				return err
+			}
+		}
 	}
 
 	methodName := funcNode.Name.Name
-	paramTypes := make([]string, 0, len(funcNode.Type.Params.List))
+	params := make([]*dataStruct, 0, len(funcNode.Type.Params.List))
 
 	// Первый параметры контекст, его пропускаем
 	for _, param := range funcNode.Type.Params.List[1:] {
-		var typeNameBuf bytes.Buffer
-		err := printer.Fprint(&typeNameBuf, fSet, param.Type)
+		s, err := ctx.resolveParamStruct(param.Type, file)
 		if err != nil {
+			return fmt.Errorf("%s.%s: %v", objectName, methodName, err)
+		}
+		if err = ctx.resolveValidateHooks(s, objectName); err != nil {
+			return fmt.Errorf("%s.%s: %s: %v", objectName, methodName, s.Name, err)
+		}
+		params = append(params, s)
+	}
+
+	if handlerMethodSpecs.Timeout != "" {
+		if err = ctx.data.Imports.add("context", "context"); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range handlerMethodSpecs.Middleware {
+		if err = ctx.checkMiddleware(name); err != nil {
+			return fmt.Errorf("%s.%s: middleware %s: %v", objectName, methodName, name, err)
+		}
+	}
+
+	// Первый результат - ответ метода, второй - ошибка
+	var result ast.Expr
+	if results := funcNode.Type.Results; results != nil && len(results.List) == 2 {
+		result = results.List[0].Type
+	}
+
+	route, err := parseUrlRoute(handlerMethodSpecs.Url, "route"+objectName+methodName)
+	if err != nil {
+		return fmt.Errorf("%s.%s: %v", objectName, methodName, err)
+	}
+	if route != nil {
+		if err = route.check(params); err != nil {
+			return fmt.Errorf("%s.%s: %v", objectName, methodName, err)
+		}
+		if err = ctx.data.Imports.add("regexp", "regexp"); err != nil {
 			return err
 		}
-		paramTypes = append(paramTypes, typeNameBuf.String())
 	}
 
 	(*(*handlers)[objectName].Methods)[methodName] = &handlerMethod{
 		methodName,
 		objectName,
 		handlerMethodSpecs,
-		paramTypes,
+		params,
+		(*handlers)[objectName],
+		route,
+		result,
+		file,
 	}
 
 	return nil
 }
 
-func tryParseDataStruct(genNode *ast.GenDecl, structs *dataStructs) error {
+// JSON из комментария вида "// apigen:api {...}", пустая строка - метки нет
+func findAnnotation(doc *ast.CommentGroup, label string) (string, error) {
+	if doc == nil {
+		return "", nil
+	}
+	for _, comment := range doc.List {
+		if index := strings.Index(comment.Text, label); index != -1 {
+			index += len(label)
+			if index+2 >= len(comment.Text) {
+				return "", fmt.Errorf("%s", comment.Text)
+			}
+			return comment.Text[index:], nil
+		}
+	}
+	return "", nil
+}
+
+func tryParseDataStruct(genNode *ast.GenDecl, file *sourceFile, pkg *sourcePackage, structs *dataStructs) error {
 	for _, spec := range genNode.Specs {
 		currType, ok := spec.(*ast.TypeSpec)
 		if !ok {
@@ -429,73 +796,113 @@ func tryParseDataStruct(genNode *ast.GenDecl, structs *dataStructs) error {
 		}
 
 		structName := currType.Name.Name
+		fields := dataStructFields(make([]*dataStructField, 0, len(currStruct.Fields.List)))
 
-		hasFields := false
-		fields := dataStructFields(make(map[string]*dataStructField))
-
-	FieldsLoop:
 		for _, fieldNode := range currStruct.Fields.List {
-			if fieldNode.Tag == nil {
-				continue FieldsLoop
+			var validator *apiValidator
+			if fieldNode.Tag != nil {
+				tag := reflect.StructTag(fieldNode.Tag.Value[1 : len(fieldNode.Tag.Value)-1])
+				if tagValue, ok := tag.Lookup("apivalidator"); ok {
+					var err error
+					if validator, err = parseApiValidatorTagValue(tagValue); err != nil {
+						return err
+					}
+				}
 			}
-			tag := reflect.StructTag(fieldNode.Tag.Value[1 : len(fieldNode.Tag.Value)-1])
-			var tagValue string
-			if tagValue, ok = tag.Lookup("apivalidator"); !ok {
-				continue FieldsLoop
+
+			// Встроенная структура, её поля становятся параметрами этой структуры
+			if len(fieldNode.Names) == 0 {
+				fields = append(fields, &dataStructField{
+					Name:       embeddedFieldName(fieldNode.Type),
+					Validator:  validator,
+					StructType: fieldNode.Type,
+					Embedded:   true,
+				})
+				continue
 			}
 
-			validator, err := parseApiValidatorTagValue(tagValue)
+			fieldType, isSlice, isPointer, err := parseFieldType(fieldNode.Type)
 			if err != nil {
-				return err
+				// Возможно, это вложенная структура - проверим, когда понадобится её разбирать
+				for _, name := range fieldNode.Names {
+					fields = append(fields, &dataStructField{
+						Name:       name.Name,
+						Validator:  validator,
+						StructType: fieldNode.Type,
+					})
+				}
+				continue
 			}
 
-			fieldName := fieldNode.Names[0].Name
-			fileType := fieldNode.Type.(*ast.Ident).Name
-			var fieldTypeEnum FieldTypeEnum
-			switch fileType {
-			case "int":
-				fieldTypeEnum = Int
-			case "string":
-				fieldTypeEnum = String
-			default:
-				return fmt.Errorf("invalid filed type: %s", fileType)
+			if validator == nil {
+				continue
 			}
 
-			hasFields = true
-			fields[fieldName] = &dataStructField{
-				fieldName,
-				fieldTypeEnum,
-				validator,
+			for _, name := range fieldNode.Names {
+				if err = checkApiValidator(validator, fieldType); err != nil {
+					return fmt.Errorf("%s.%s: %v", structName, name.Name, err)
+				}
+
+				fields = append(fields, &dataStructField{
+					Name:      name.Name,
+					Type:      fieldType,
+					IsSlice:   isSlice,
+					IsPointer: isPointer,
+					Validator: validator,
+				})
 			}
 		}
 
-		if hasFields {
-			(*structs)[structName] = &dataStruct{
-				structName,
-				&fields,
-			}
+		// Комментарий к type X struct без скобок относится ко всему объявлению
+		doc := currType.Doc
+		if doc == nil && !genNode.Lparen.IsValid() {
+			doc = genNode.Doc
+		}
+
+		(*structs)[structName] = &dataStruct{
+			Decl:    currStruct,
+			Doc:     doc,
+			Name:    structName,
+			Ident:   structName,
+			Fields:  &fields,
+			File:    file,
+			Package: pkg,
 		}
 	}
 
 	return nil
 }
 
+// Имя встроенного поля совпадает с именем типа: Pagination, *Pagination, dto.Pagination
+func embeddedFieldName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedFieldName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
 func parseApiValidatorTagValue(tagValue string) (*apiValidator, error) {
 	if len(tagValue) == 0 {
 		return nil, fmt.Errorf("empty tagValue")
 	}
-	dict := strings.Split(tagValue, ",")
+	dict := splitTagValue(tagValue)
 	res := apiValidator{}
 	for _, kv := range dict {
 		if len(kv) == 0 {
 			return nil, fmt.Errorf("empty tagValue kv")
 		}
-		if strings.Contains(kv, "required") {
+		if kv == "required" {
 			res.Required = true
 			continue
 		}
 
-		kvArr := strings.Split(kv, "=")
+		// В pattern может встретиться "="
+		kvArr := strings.SplitN(kv, "=", 2)
 		if len(kvArr) != 2 || len(kvArr[0]) == 0 || len(kvArr[1]) == 0 {
 			return nil, fmt.Errorf("invalid tagValue kv: %s", kv)
 		}
@@ -514,6 +921,22 @@ func parseApiValidatorTagValue(tagValue string) (*apiValidator, error) {
 			res.Min = value
 		case "max":
 			res.Max = value
+		case "minlen":
+			res.MinLen = value
+		case "maxlen":
+			res.MaxLen = value
+		case "pattern":
+			res.Pattern = value
+		case "format":
+			res.Format = value
+		case "validate":
+			res.Validate = value
+		case "gtfield", "gtefield", "ltfield", "ltefield", "required_if", "excluded_with":
+			tag, err := parseCrossFieldTag(key, value)
+			if err != nil {
+				return nil, err
+			}
+			res.CrossFields = append(res.CrossFields, tag)
 		default:
 			return nil, fmt.Errorf("unexpected tagValue key: %s", key)
 		}
@@ -522,53 +945,660 @@ func parseApiValidatorTagValue(tagValue string) (*apiValidator, error) {
 	return &res, nil
 }
 
+// Делит значение тега по запятым, кроме запятых внутри {} и [] - они бывают в pattern: {2,3}, [,;]
+func splitTagValue(tagValue string) []string {
+	res := make([]string, 0)
+	depth, start := 0, 0
+	for i := 0; i < len(tagValue); i++ {
+		switch tagValue[i] {
+		case '\\':
+			i++
+		case '{', '[':
+			depth++
+		case '}', ']':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				res = append(res, tagValue[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(res, tagValue[start:])
+}
+
+// Всё, что нужно для генерации файла с обработчиками
+type generatorData struct {
+	Handlers *handlerObjects
+	Structs  *dataStructs
+	Imports  *packageImports
+	// Нужен, чтобы после разбора находить типы результатов методов
+	ctx *parseContext
+	// Файл пакета, для которого генерируется код, пусто - весь пакет
+	target string
+}
+
 type handlerObjects map[string]*handlerObject
 
+func (h handlerObjects) sorted() []*handlerObject {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := make([]*handlerObject, 0, len(h))
+	for _, name := range names {
+		res = append(res, h[name])
+	}
+	return res
+}
+
 type handlerObject struct {
 	Name    string
 	Methods *handlerMethods
+	// Поле типа Authenticator, если обработчику можно передать свою проверку авторизации
+	AuthField string
+	Specs     *HandlerSpecs
+}
+
+// Первый по имени файл с методами обработчика: при генерации для каждого файла отдельно
+// ServeHTTP со всеми маршрутами и get* попадают только в его результат
+func (h *handlerObject) OwnerFile() string {
+	var owner string
+	for _, method := range *h.Methods {
+		if owner == "" || method.File.Path < owner {
+			owner = method.File.Path
+		}
+	}
+	return owner
+}
+
+// Методы с параметрами пути в порядке проверки в ServeHTTP: url, все пути которого подходят и другому url,
+// проверяется раньше него (/items/{id:int} раньше /items/{name})
+func (h *handlerObject) RouteMethods() []*handlerMethod {
+	res := make([]*handlerMethod, 0)
+	for _, method := range h.Methods.sorted() {
+		if method.Route != nil {
+			res = append(res, method)
+		}
+	}
+
+	// у более узкого url больше url, в которые он вложен
+	wider := make(map[*handlerMethod]int)
+	for _, a := range res {
+		for _, b := range res {
+			if _, onlyA, onlyB := a.Route.compare(b.Route); a != b && !onlyA && onlyB {
+				wider[a]++
+			}
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return wider[res[i]] > wider[res[j]]
+	})
+	return res
+}
+
+// Один путь не должен подходить двум url, если ни один из них не вложен в другой: иначе метод
+// зависел бы от порядка проверки. Url без параметров проверяются раньше остальных и должны быть разными
+func (h *handlerObject) checkRoutes() error {
+	methods := h.Methods.sorted()
+	static := make(map[string]string)
+	for _, method := range methods {
+		if method.Route != nil {
+			continue
+		}
+		if other, ok := static[method.Specs.Url]; ok {
+			return fmt.Errorf("methods %s and %s have the same url %s", other, method.Name, method.Specs.Url)
+		}
+		static[method.Specs.Url] = method.Name
+	}
+
+	for i, a := range methods {
+		for _, b := range methods[i+1:] {
+			if a.Route == nil || b.Route == nil {
+				continue
+			}
+			common, onlyA, onlyB := a.Route.compare(b.Route)
+			if common && onlyA == onlyB {
+				return fmt.Errorf("ambiguous urls %s (%s) and %s (%s): some paths match both and neither url is narrower",
+					a.Specs.Url, a.Name, b.Specs.Url, b.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (h *handlerObject) hasMethodsIn(file string) bool {
+	for _, method := range *h.Methods {
+		if method.File.Path == file {
+			return true
+		}
+	}
+	return false
+}
+
+// Зависимость обёрток из apigen: сгенерированный get{{.Interface}}() берёт её из поля Field,
+// затем из самого обработчика, если он реализует интерфейс, иначе возвращает Default
+type handlerGetter struct {
+	Handler   string
+	Interface string
+	Field     string
+	Default   string
+}
+
+func (h *handlerObject) Getters() []handlerGetter {
+	return []handlerGetter{
+		{h.Name, "Authenticator", h.AuthField, "apigen.DefaultAuthenticator"},
+		{h.Name, "Encoder", "", h.Specs.EncoderExpr()},
+		{h.Name, "Logger", "", "apigen.DefaultLogger"},
+		{h.Name, "Metrics", "", "apigen.DefaultMetrics"},
+		{h.Name, "Limiter", "", "apigen.DefaultLimiter"},
+		{h.Name, "Cache", "", "apigen.DefaultCache"},
+	}
+}
+
+// Настройки структуры обработчика из комментария "// apigen:handler {...}" к её объявлению
+type HandlerSpecs struct {
+	// Формат ответов: "envelope", "json" или "problem", по умолчанию - apigen.DefaultEncoder
+	Encoder string
+	// На неподходящий HTTP-метод - 405 "method not allowed", а не 406 "bad method" как в исходном задании
+	StrictMethods bool
+	// ID запроса в теле каждой ошибки, в том числе созданный обёрткой
+	RequestID bool
+}
+
+// Статус ответа на неподходящий HTTP-метод
+func (specs *HandlerSpecs) BadMethodStatus() int {
+	if specs.StrictMethods {
+		return http.StatusMethodNotAllowed
+	}
+	return http.StatusNotAcceptable
+}
+
+var handlerEncoders = map[string]string{
+	"":         "apigen.DefaultEncoder",
+	"envelope": "apigen.EnvelopeEncoder{}",
+	"json":     "apigen.JSONEncoder{}",
+	"problem":  "apigen.ProblemEncoder{}",
+}
+
+// Кодировщик ответов для обработчика, который сам не реализует apigen.Encoder.
+// С RequestID без encoder - EnvelopeEncoder, формат DefaultEncoder по умолчанию
+func (specs *HandlerSpecs) EncoderExpr() string {
+	if !specs.RequestID {
+		return handlerEncoders[specs.Encoder]
+	}
+	encoder := specs.Encoder
+	if encoder == "" {
+		encoder = "envelope"
+	}
+	return strings.TrimSuffix(handlerEncoders[encoder], "}") + "RequestID: true}"
+}
+
+// Ответ метода в обёртке {"error": "", "response": ...}
+func (specs *HandlerSpecs) Envelope() bool {
+	return specs.Encoder == "" || specs.Encoder == "envelope"
+}
+
+func (specs *HandlerSpecs) check() error {
+	if _, ok := handlerEncoders[specs.Encoder]; !ok {
+		return fmt.Errorf("unknown encoder %q, expected \"envelope\", \"json\" or \"problem\"", specs.Encoder)
+	}
+	return nil
 }
 
 type handlerMethods map[string]*handlerMethod
 
+func (m handlerMethods) sorted() []*handlerMethod {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := make([]*handlerMethod, 0, len(m))
+	for _, name := range names {
+		res = append(res, m[name])
+	}
+	return res
+}
+
 // Метод структуры обработчика
 type handlerMethod struct {
 	Name       string
 	ObjectName string
 	Specs      *HandlerMethodSpecs
-	ParamTypes []string
+	Params     []*dataStruct
+	Handler    *handlerObject
+	// nil, если в url нет параметров
+	Route *urlRoute
+	// Тип ответа метода и файл, в котором объявлен метод
+	Result ast.Expr
+	File   *sourceFile
+}
+
+// Обработчик, метод и url для ключей apigen.Limiter и apigen.Cache: у обработчиков с одним url они разные
+func (m *handlerMethod) Endpoint() string {
+	return m.ObjectName + "." + m.Name + " " + m.Specs.Url
 }
 
 type HandlerMethodSpecs struct {
 	Url    string
 	Auth   bool
-	Method string
+	Method HttpMethods
+	// Роли, хотя бы одна из которых должна быть у автора запроса
+	Roles []string
+	// "first" (по умолчанию) - ответ с первой ошибкой параметров, "all" - со всеми
+	Errors string
+	// Функции пакета func(http.Handler) http.Handler, первая - внешняя
+	Middleware []string
+	// Время на проверку параметров и вызов метода: "500ms", "2s"
+	Timeout string
+	// "10/s" или {"per": "auth", "rate": "100/m"}
+	RateLimit *RateLimitSpecs
+	// "30s" или {"ttl": "30s", "memory": true}
+	Cache *CacheSpecs
+}
+
+// Таймаут для context.WithTimeout, пустая строка - без таймаута. Строка уже проверена check
+func (specs *HandlerMethodSpecs) TimeoutExpr() string {
+	if specs.Timeout == "" {
+		return ""
+	}
+	timeout, _ := time.ParseDuration(specs.Timeout)
+	return "time.Duration(" + strconv.FormatInt(int64(timeout), 10) + ")"
+}
+
+// Middleware в порядке оборачивания: первой оборачивается последняя
+func (specs *HandlerMethodSpecs) MiddlewareChain() []string {
+	res := make([]string, 0, len(specs.Middleware))
+	for i := len(specs.Middleware) - 1; i >= 0; i-- {
+		res = append(res, specs.Middleware[i])
+	}
+	return res
+}
+
+// HTTP-методы из "method": "POST" или "method": ["GET", "POST"], пустой - без ограничения
+type HttpMethods []string
+
+func (m *HttpMethods) UnmarshalJSON(data []byte) error {
+	var methods []string
+	if err := json.Unmarshal(data, &methods); err != nil {
+		var method string
+		if err = json.Unmarshal(data, &method); err != nil {
+			return fmt.Errorf("method must be string or array of strings")
+		}
+		if method != "" {
+			methods = []string{method}
+		}
+	}
+
+	*m = make(HttpMethods, 0, len(methods))
+	for _, method := range methods {
+		method = strings.ToUpper(method)
+		if !contains(*m, method) {
+			*m = append(*m, method)
+		}
+	}
+	return nil
+}
+
+// Методы, которые принимает обёртка: к GET добавляется HEAD
+func (m HttpMethods) Allowed() []string {
+	res := make([]string, 0, len(m)+1)
+	for _, method := range m {
+		res = append(res, method)
+		if method == http.MethodGet && !contains(m, http.MethodHead) {
+			res = append(res, http.MethodHead)
+		}
+	}
+	return res
+}
+
+// OPTIONS обрабатывается автоматически, если метод сам его не принимает
+func (m HttpMethods) AutoOptions() bool {
+	return !contains(m, http.MethodOptions)
+}
+
+// Методы стандарта HTTP, которые принимает метод без ограничения, кроме CONNECT и TRACE
+var anyHttpMethods = HttpMethods{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// Заголовок Allow, для метода без ограничения - все методы из anyHttpMethods
+func (m HttpMethods) Allow() string {
+	allowed := anyHttpMethods.Allowed()
+	if len(m) > 0 {
+		allowed = m.Allowed()
+	}
+	if m.AutoOptions() {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	return strings.Join(allowed, ", ")
+}
+
+// Метод для клиента: первый из перечисленных, без ограничения - GET
+func (m HttpMethods) First() string {
+	if len(m) == 0 {
+		return http.MethodGet
+	}
+	return m[0]
+}
+
+func (m HttpMethods) check() error {
+	for _, method := range m {
+		if method == "" || strings.IndexFunc(method, func(r rune) bool { return r < 'A' || r > 'Z' }) != -1 {
+			return fmt.Errorf("invalid method %q", method)
+		}
+	}
+	return nil
+}
+
+func contains(arr []string, item string) bool {
+	for _, i := range arr {
+		if item == i {
+			return true
+		}
+	}
+	return false
+}
+
+func (specs *HandlerMethodSpecs) CollectErrors() bool {
+	return specs.Errors == "all"
+}
+
+func (specs *HandlerMethodSpecs) check() error {
+	if len(specs.Roles) > 0 && !specs.Auth {
+		return fmt.Errorf("roles require \"auth\": true")
+	}
+	for _, role := range specs.Roles {
+		if role == "" {
+			return fmt.Errorf("empty role")
+		}
+	}
+	if err := specs.Method.check(); err != nil {
+		return err
+	}
+	if specs.Timeout != "" {
+		if timeout, err := time.ParseDuration(specs.Timeout); err != nil || timeout <= 0 {
+			return fmt.Errorf("invalid timeout %q, expected positive duration like \"500ms\"", specs.Timeout)
+		}
+	}
+	if specs.RateLimit != nil {
+		if err := specs.RateLimit.check(); err != nil {
+			return err
+		}
+		if specs.RateLimit.PerAuth() && !specs.Auth {
+			return fmt.Errorf("ratelimit per auth requires \"auth\": true")
+		}
+	}
+	if specs.Cache != nil {
+		if err := specs.Cache.check(); err != nil {
+			return err
+		}
+		if len(specs.Method) > 0 && !contains(specs.Method, http.MethodGet) {
+			return fmt.Errorf("cache requires GET in method")
+		}
+	}
+	if specs.Errors != "" && specs.Errors != "first" && specs.Errors != "all" {
+		return fmt.Errorf("unknown errors mode %q, expected \"first\" or \"all\"", specs.Errors)
+	}
+	return nil
+}
+
+// Ограничение частоты запросов к методу: Rate - "число/период", период - s, m, h или длительность вроде "10s".
+// Per - ключ ограничения: "ip" (по умолчанию) или "auth" - автор запроса, только для "auth": true
+type RateLimitSpecs struct {
+	Per  string
+	Rate string
+
+	limit int
+	per   time.Duration
+}
+
+func (specs *RateLimitSpecs) UnmarshalJSON(data []byte) error {
+	var rate string
+	if err := json.Unmarshal(data, &rate); err == nil {
+		specs.Rate = rate
+		return nil
+	}
+	var object struct {
+		Per  string
+		Rate string
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("ratelimit must be string or object with per and rate")
+	}
+	specs.Per, specs.Rate = object.Per, object.Rate
+	return nil
+}
+
+func (specs *RateLimitSpecs) check() error {
+	if specs.Per != "" && specs.Per != "ip" && specs.Per != "auth" {
+		return fmt.Errorf("unknown ratelimit per %q, expected \"ip\" or \"auth\"", specs.Per)
+	}
+	invalid := fmt.Errorf("invalid ratelimit rate %q, expected like \"10/s\" or \"100/m\"", specs.Rate)
+	parts := strings.Split(specs.Rate, "/")
+	if len(parts) != 2 {
+		return invalid
+	}
+	limit, err := strconv.Atoi(parts[0])
+	if err != nil || limit <= 0 {
+		return invalid
+	}
+	per, ok := map[string]time.Duration{"s": time.Second, "m": time.Minute, "h": time.Hour}[parts[1]]
+	if !ok {
+		if per, err = time.ParseDuration(parts[1]); err != nil || per <= 0 {
+			return invalid
+		}
+	}
+	specs.limit, specs.per = limit, per
+	return nil
+}
+
+func (specs *RateLimitSpecs) PerAuth() bool {
+	return specs.Per == "auth"
+}
+
+func (specs *RateLimitSpecs) Key() string {
+	if specs.PerAuth() {
+		return "auth"
+	}
+	return "ip"
+}
+
+// apigen.Rate для обёртки, после check
+func (specs *RateLimitSpecs) RateExpr() string {
+	return fmt.Sprintf("apigen.Rate{Limit: %d, Per: time.Duration(%d)}", specs.limit, int64(specs.per))
+}
+
+// Кеширование ответов на GET: TTL - max-age в Cache-Control, Memory - хранить ответы в apigen.Cache на TTL
+type CacheSpecs struct {
+	TTL    string
+	Memory bool
+
+	ttl time.Duration
+}
+
+func (specs *CacheSpecs) UnmarshalJSON(data []byte) error {
+	var ttl string
+	if err := json.Unmarshal(data, &ttl); err == nil {
+		specs.TTL = ttl
+		return nil
+	}
+	var object struct {
+		TTL    string
+		Memory bool
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("cache must be string or object with ttl and memory")
+	}
+	specs.TTL, specs.Memory = object.TTL, object.Memory
+	return nil
+}
+
+func (specs *CacheSpecs) check() error {
+	ttl, err := time.ParseDuration(specs.TTL)
+	if err != nil || ttl <= 0 {
+		return fmt.Errorf("invalid cache ttl %q, expected positive duration like \"30s\"", specs.TTL)
+	}
+	specs.ttl = ttl
+	return nil
+}
+
+// TTL для обёртки, после check
+func (specs *CacheSpecs) TTLExpr() string {
+	return "time.Duration(" + strconv.FormatInt(int64(specs.ttl), 10) + ")"
 }
 
 type dataStructs map[string]*dataStruct
 
+func (d dataStructs) sorted() []*dataStruct {
+	names := make([]string, 0, len(d))
+	for name := range d {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := make([]*dataStruct, 0, len(d))
+	for _, name := range names {
+		res = append(res, d[name])
+	}
+	return res
+}
+
+// Импорты сгенерированного файла: имя пакета -> путь
+type packageImports map[string]string
+
+type packageImport struct {
+	Alias string
+	Path  string
+}
+
+// Нужно ли указывать имя пакета при импорте
+func (i packageImport) Named() bool {
+	return path.Base(i.Path) != i.Alias
+}
+
+func (i packageImports) add(alias, importPath string) error {
+	if existing, ok := i[alias]; ok && existing != importPath {
+		return fmt.Errorf("package name %s is used for both %s and %s", alias, existing, importPath)
+	}
+	i[alias] = importPath
+	return nil
+}
+
+func (i packageImports) sorted() []packageImport {
+	res := make([]packageImport, 0, len(i))
+	for alias, importPath := range i {
+		res = append(res, packageImport{alias, importPath})
+	}
+	sort.Slice(res, func(a, b int) bool {
+		return res[a].Path < res[b].Path
+	})
+	return res
+}
+
 // Описание структуры с тегом apivalidator
 type dataStruct struct {
-	Name   string
+	// Имя типа в сгенерированном коде, для структур из других пакетов - с именем пакета
+	Name string
+	// Имя, пригодное для идентификаторов: validateAndBuild{{.Ident}}
+	Ident  string
 	Fields *dataStructFields
+	// Объявление структуры со всеми полями, нужно для описания ответов методов
+	Decl *ast.StructType
+	Doc  *ast.CommentGroup
+	// Где объявлена структура, нужно для поиска типов вложенных структур
+	File    *sourceFile
+	Package *sourcePackage
+	// Параметры запроса в порядке следования полей, заполняются при разборе метода
+	Params *structParams
+	// Указатели на вложенные структуры, которые надо создать до заполнения параметров
+	Allocs []*structAlloc
+	// Правила, сравнивающие поля, проверяются после всех полей
+	CrossRules []*crossFieldRule
+	// Границы min/max/minlen/maxlen всех параметров
+	Limits []*limitDecl
 }
 
-// Набор полей структуры
-type dataStructFields map[string]*dataStructField
+func (s *dataStruct) HasParams() bool {
+	return s.Params != nil && len(*s.Params) > 0
+}
+
+// Поля структуры в порядке объявления
+type dataStructFields []*dataStructField
 
 // Поле структуры
 type dataStructField struct {
-	Name      string
-	Type      FieldTypeEnum
+	Name string
+	Type FieldTypeEnum
+	// []T - значения из повторяющихся параметров
+	IsSlice bool
+	// *T - необязательное поле, nil если параметр не пришёл
+	IsPointer bool
 	Validator *apiValidator
+	// Тип вложенной или встроенной структуры, nil для полей простых типов
+	StructType ast.Expr
+	Embedded   bool
+}
+
+// Параметр запроса, в который превращается поле структуры или вложенной в неё структуры
+type structParam struct {
+	*dataStructField
+	// Путь до поля: res.{{.Path}}
+	Path string
+	// Имя для переменных сгенерированного кода
+	Var       string
+	ParamName string
+	// Переменная с регулярным выражением из pattern
+	PatternVar string
+	Hook       *validateHook
+	// Проверки разобранного значения в порядке правил тега
+	Checks []*valueCheck
 }
 
-type FieldTypeEnum int
+func (p *structParam) FormatFunc() string {
+	return stringFormats[p.Validator.Format].Func
+}
 
-const (
-	Int FieldTypeEnum = iota
-	String
-)
+// Ошибка параметра в сгенерированном коде, message дописывается к имени параметра
+func (p *structParam) FieldError(rule, message string) string {
+	return "apigen.FieldError{Param: " + strconv.Quote(p.ParamName) + ", Rule: " + strconv.Quote(rule) +
+		", Message: " + strconv.Quote(p.ParamName+message) + "}"
+}
+
+// Значения enum литералами типа поля: сравнивается разобранное значение, поэтому для int
+// "01" подходит под enum=1, а для time.Duration "60s" - под enum=1m. Одинаковые значения
+// склеиваются, иначе switch не скомпилируется
+func (p *structParam) EnumCases() string {
+	cases := make([]string, 0, len(p.Validator.Enum))
+	seen := make(map[string]bool)
+	for _, value := range p.Validator.Enum {
+		literal := goLiteral(p.Type, value)
+		if !seen[literal] {
+			seen[literal] = true
+			cases = append(cases, literal)
+		}
+	}
+	return strings.Join(cases, ", ")
+}
+
+func (p *structParam) EnumMessage() string {
+	return " must be one of [" + strings.Join(p.Validator.Enum, ", ") + "]"
+}
+
+func (p *structParam) TypeMessage() string {
+	return " must be " + p.Type.Title()
+}
+
+type structParams []*structParam
+
+type structAlloc struct {
+	Path string
+	Type string
+}
 
 type apiValidator struct {
 	Required  bool
@@ -577,6 +1607,13 @@ type apiValidator struct {
 	Default   string
 	Min       string
 	Max       string
+	MinLen    string
+	MaxLen    string
+	Pattern   string
+	Format    string
+	// Имя функции или метода обработчика с дополнительной проверкой
+	Validate    string
+	CrossFields []crossFieldTag
 }
 
 func fPrintln(w io.Writer, p ...interface{}) {
@@ -0,0 +1,494 @@
+package main
+
+import (
+	"bytes"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Сгенерированные файлы лежат в репозитории и компилируются вместе с тестами,
+// поэтому должны совпадать с тем, что генератор выдаёт сейчас
+func TestGeneratedFilesUpToDate(t *testing.T) {
+	cases := []struct {
+		Target string
+		Output string
+	}{
+		{"../api.go", "../api_handlers.go"},
+		{"../fixture", "../fixture/handlers.go"},
+		// каждый файл пакета отдельно, ListParams нужна обоим
+		{"../fixture/perfile/orders.go", "../fixture/perfile/orders_handlers.go"},
+		{"../fixture/perfile/users.go", "../fixture/perfile/users_handlers.go"},
+		// методы UsersApi в двух файлах, ServeHTTP - только в users_handlers.go
+		{"../fixture/perfile/users_admin.go", "../fixture/perfile/users_admin_handlers.go"},
+	}
+
+	for _, item := range cases {
+		_, code, err := generate(item.Target, item.Output)
+		if err != nil {
+			t.Errorf("%s: %v", item.Target, err)
+			continue
+		}
+		existing, err := ioutil.ReadFile(item.Output)
+		if err != nil {
+			t.Errorf("%s: %v", item.Output, err)
+			continue
+		}
+		if !bytes.Equal(code, existing) {
+			t.Errorf("%s is out of date: go build handlers_gen/* && ./codegen %s %s",
+				item.Output, strings.TrimPrefix(item.Target, "../"), strings.TrimPrefix(item.Output, "../"))
+		}
+	}
+}
+
+func TestLoadTarget(t *testing.T) {
+	cases := []struct {
+		Target string
+		Output string
+		// Файл, из которого берутся обработчики, пусто - весь пакет
+		TargetFile string
+	}{
+		// результат прошлого запуска не разбирается
+		{"../fixture", "../fixture/handlers.go", ""},
+		{"../fixture", "", ""},
+		// для файла разбирается весь пакет, обработчики - только из файла
+		{"../fixture/search.go", "../fixture/handlers.go", "search.go"},
+	}
+
+	for idx, item := range cases {
+		pkg, err := newPackageLoader(token.NewFileSet()).loadTarget(item.Target, item.Output)
+		if err != nil {
+			t.Errorf("[%d] unexpected error: %v", idx, err)
+			continue
+		}
+		files := make([]string, 0, len(*pkg.Files))
+		for _, f := range *pkg.Files {
+			files = append(files, filepath.Base(f.Path))
+		}
+		// все файлы пакета, кроме тестов и item.Output
+		expected := []string{}
+		paths, _ := filepath.Glob("../fixture/*.go")
+		for _, path := range paths {
+			if !strings.HasSuffix(path, "_test.go") && path != item.Output {
+				expected = append(expected, filepath.Base(path))
+			}
+		}
+		if !equalStrings(files, expected) {
+			t.Errorf("[%d] expected files %v, got %v", idx, expected, files)
+		}
+		target := ""
+		if pkg.Target != "" {
+			target = filepath.Base(pkg.Target)
+		}
+		if target != item.TargetFile {
+			t.Errorf("[%d] expected target %q, got %q", idx, item.TargetFile, target)
+		}
+	}
+
+	if _, err := newPackageLoader(token.NewFileSet()).loadTarget("../fixture/search_test.go", ""); err == nil {
+		t.Errorf("expected error for file outside of package build")
+	}
+}
+
+func TestParseImportedParams(t *testing.T) {
+	data, _, err := generate("../fixture/search.go", "../fixture/handlers.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, ok := (*data.Structs)["dto.FindParams"]
+	if !ok {
+		t.Fatalf("expected dto.FindParams in structs, got %v", data.Structs.sorted())
+	}
+	if s.Ident != "DtoFindParams" || s.Package.ImportPath != "github.com/momsspaghettti/coursera-golang-webservices-2/Week_1/hw5_codegen/fixture/dto" {
+		t.Errorf("unexpected struct %s from %s", s.Ident, s.Package.ImportPath)
+	}
+	if (*data.Imports)["dto"] != s.Package.ImportPath {
+		t.Errorf("expected dto import, got %v", data.Imports.sorted())
+	}
+}
+
+// Общая структура параметров генерируется только в файле, который ею владеет
+func TestKeepTarget(t *testing.T) {
+	cases := []struct {
+		Target   string
+		Handlers []string
+		Structs  []string
+	}{
+		{"../fixture/perfile/orders.go", []string{"OrdersApi"}, []string{"ListParams"}},
+		{"../fixture/perfile/users.go", []string{"UsersApi"}, []string{"UserParams"}},
+		{"../fixture/perfile/users_admin.go", []string{"UsersApi"}, []string{"BlockParams"}},
+		{"../fixture/perfile", []string{"OrdersApi", "UsersApi"}, []string{"BlockParams", "ListParams", "UserParams"}},
+	}
+
+	for _, item := range cases {
+		data, _, err := generate(item.Target, "")
+		if err != nil {
+			t.Errorf("%s: %v", item.Target, err)
+			continue
+		}
+		handlers := make([]string, 0)
+		for _, handler := range data.Handlers.sorted() {
+			handlers = append(handlers, handler.Name)
+		}
+		structs := make([]string, 0)
+		for _, s := range data.Structs.sorted() {
+			structs = append(structs, s.Name)
+		}
+		if !equalStrings(handlers, item.Handlers) || !equalStrings(structs, item.Structs) {
+			t.Errorf("%s: expected %v and %v, got %v and %v", item.Target, item.Handlers, item.Structs, handlers, structs)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Source string
+		// Подстрока ошибки генерации, пусто - код генерируется
+		Error string
+	}{
+		{"roles with auth", `
+type Params struct{}
+
+// apigen:api {"url": "/admin", "auth": true, "roles": ["admin"]}
+func (api *Api) Admin(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, ""},
+		{"roles without auth", `
+type Params struct{}
+
+// apigen:api {"url": "/admin", "roles": ["admin"]}
+func (api *Api) Admin(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, `roles require "auth": true`},
+		{"unknown url param", `
+type Params struct {
+	ID int 'apivalidator:"min=1"'
+}
+
+// apigen:api {"url": "/item/{key}"}
+func (api *Api) Item(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "url param key is not found in params struct"},
+		{"unknown url param type", `
+type Params struct {
+	ID int 'apivalidator:"min=1"'
+}
+
+// apigen:api {"url": "/item/{id:float}"}
+func (api *Api) Item(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "unknown param type: {id:float}"},
+		{"int url param bound to bool", `
+type Params struct {
+	ID bool 'apivalidator:"paramname=id"'
+}
+
+// apigen:api {"url": "/item/{id:int}"}
+func (api *Api) Item(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "url param {id:int} can't be bound to bool field"},
+		{"nested routes", `
+type Params struct {
+	ID   int    'apivalidator:"paramname=id"'
+	Name string 'apivalidator:"paramname=name"'
+}
+
+// apigen:api {"url": "/item/{name}"}
+func (api *Api) ByName(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+
+// apigen:api {"url": "/item/{id:int}"}
+func (api *Api) ByID(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+
+// apigen:api {"url": "/item/{name}/{id:uint}"}
+func (api *Api) Version(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, ""},
+		{"overlapping routes", `
+type Params struct {
+	ID   int    'apivalidator:"paramname=id"'
+	Name string 'apivalidator:"paramname=name"'
+}
+
+// apigen:api {"url": "/item/{name}/edit"}
+func (api *Api) Edit(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+
+// apigen:api {"url": "/item/new/{name}"}
+func (api *Api) New(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "ambiguous urls /item/{name}/edit (Edit) and /item/new/{name} (New)"},
+		{"same routes", `
+type Params struct {
+	ID   int    'apivalidator:"paramname=id"'
+	Name string 'apivalidator:"paramname=name"'
+}
+
+// apigen:api {"url": "/item/{id:int}"}
+func (api *Api) A(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+
+// apigen:api {"url": "/item/{name}"}
+func (api *Api) B(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+
+// apigen:api {"url": "/item/{id:int}"}
+func (api *Api) C(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "ambiguous urls /item/{id:int} (A) and /item/{id:int} (C)"},
+		{"params in one segment", `
+type Params struct {
+	ID   int    'apivalidator:"paramname=id"'
+	Name string 'apivalidator:"paramname=name"'
+}
+
+// apigen:api {"url": "/item/{id:int}-{name}"}
+func (api *Api) A(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+
+// apigen:api {"url": "/item/{name}-1"}
+func (api *Api) B(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "ambiguous urls /item/{id:int}-{name} (A) and /item/{name}-1 (B)"},
+		{"same static urls", `
+type Params struct{}
+
+// apigen:api {"url": "/item"}
+func (api *Api) A(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+
+// apigen:api {"url": "/item"}
+func (api *Api) B(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "methods A and B have the same url /item"},
+		{"invalid pattern", `
+type Params struct {
+	Code string 'apivalidator:"pattern=^[A-Z{2}$"'
+}
+
+// apigen:api {"url": "/code"}
+func (api *Api) Code(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "Params.Code: invalid pattern: error parsing regexp"},
+		{"pattern on int", `
+type Params struct {
+	Code int 'apivalidator:"pattern=^[0-9]+$"'
+}
+
+// apigen:api {"url": "/code"}
+func (api *Api) Code(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "pattern, format, minlen and maxlen are supported only for string"},
+		{"unknown format", `
+type Params struct {
+	Code string 'apivalidator:"format=phone"'
+}
+
+// apigen:api {"url": "/code"}
+func (api *Api) Code(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "unknown format phone"},
+		{"min with minlen", `
+type Params struct {
+	Code string 'apivalidator:"min=1,minlen=2"'
+}
+
+// apigen:api {"url": "/code"}
+func (api *Api) Code(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "min/max and minlen/maxlen can't be used together"},
+		{"hook is not declared", `
+type Params struct {
+	Login string 'apivalidator:"validate=checkLogin"'
+}
+
+// apigen:api {"url": "/login"}
+func (api *Api) Login(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "validate=checkLogin: neither function nor method of Api"},
+		{"hook without error result", `
+type Params struct {
+	Login string 'apivalidator:"validate=checkLogin"'
+}
+
+func checkLogin(value string) bool { return true }
+
+// apigen:api {"url": "/login"}
+func (api *Api) Login(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "validate=checkLogin: must return error"},
+		{"hook with wrong first argument", `
+type Params struct {
+	Login string 'apivalidator:"validate=checkLogin"'
+}
+
+func checkLogin(n int, value string) error { return nil }
+
+// apigen:api {"url": "/login"}
+func (api *Api) Login(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "validate=checkLogin: first argument must be context.Context"},
+		{"hook with wrong value type", `
+type Params struct {
+	Login string 'apivalidator:"validate=checkLogin"'
+}
+
+func (api *Api) checkLogin(ctx context.Context, value int) error { return nil }
+
+// apigen:api {"url": "/login"}
+func (api *Api) Login(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "validate=checkLogin: must accept string value"},
+		{"hook method on one of handlers", `
+type Params struct {
+	Login string 'apivalidator:"validate=checkLogin"'
+}
+
+func (api *Api) checkLogin(value string) error { return nil }
+
+// apigen:api {"url": "/login"}
+func (api *Api) Login(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+
+type OtherApi struct{}
+
+// apigen:api {"url": "/login"}
+func (api *OtherApi) Login(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "validate=checkLogin: neither function nor method of OtherApi"},
+		{"cross rule with unknown field", `
+type Params struct {
+	Age int 'apivalidator:"gtfield=StartAge"'
+}
+
+// apigen:api {"url": "/cross"}
+func (api *Api) Cross(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "Age: gtfield=StartAge: field StartAge with apivalidator tag is not found"},
+		{"cross rule with untagged field", `
+type Params struct {
+	Age      int 'apivalidator:"gtfield=StartAge"'
+	StartAge int
+}
+
+// apigen:api {"url": "/cross"}
+func (api *Api) Cross(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "Age: gtfield=StartAge: field StartAge with apivalidator tag is not found"},
+		{"cross rule with mismatched types", `
+type Params struct {
+	Age      int   'apivalidator:"ltefield=MaxAge"'
+	MaxAge   int64 'apivalidator:"paramname=max_age"'
+}
+
+// apigen:api {"url": "/cross"}
+func (api *Api) Cross(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "Age: ltefield: can't compare int with int64 field MaxAge"},
+		{"cross rule with bool fields", `
+type Params struct {
+	Active bool 'apivalidator:"gtfield=Admin"'
+	Admin  bool 'apivalidator:"default=false"'
+}
+
+// apigen:api {"url": "/cross"}
+func (api *Api) Cross(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "can't compare bool with bool field Admin"},
+		{"required_if with invalid value", `
+type Params struct {
+	Login string 'apivalidator:"required_if=Age:old"'
+	Age   int    'apivalidator:"default=0"'
+}
+
+// apigen:api {"url": "/cross"}
+func (api *Api) Cross(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "Login: required_if: invalid int value: old"},
+		{"middleware func", `
+type Params struct{}
+
+func logRequest(next http.Handler) http.Handler { return next }
+
+// apigen:api {"url": "/mw", "middleware": ["logRequest"]}
+func (api *Api) Mw(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, ""},
+		{"middleware var", `
+type Params struct{}
+
+var logRequest = func(next http.Handler) http.Handler { return next }
+
+// apigen:api {"url": "/mw", "middleware": ["logRequest"]}
+func (api *Api) Mw(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, ""},
+		{"middleware is not declared", `
+type Params struct{}
+
+// apigen:api {"url": "/mw", "middleware": ["logRequest"]}
+func (api *Api) Mw(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "middleware logRequest: is not declared in package gentest"},
+		{"middleware with wrong argument", `
+type Params struct{}
+
+func logRequest(next http.HandlerFunc) http.Handler { return next }
+
+// apigen:api {"url": "/mw", "middleware": ["logRequest"]}
+func (api *Api) Mw(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "must be func(http.Handler) http.Handler"},
+		{"middleware without result", `
+type Params struct{}
+
+func logRequest(next http.Handler) {}
+
+// apigen:api {"url": "/mw", "middleware": ["logRequest"]}
+func (api *Api) Mw(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "must be func(http.Handler) http.Handler"},
+		{"middleware method", `
+type Params struct{}
+
+func (api *Api) logRequest(next http.Handler) http.Handler { return next }
+
+// apigen:api {"url": "/mw", "middleware": ["logRequest"]}
+func (api *Api) Mw(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, "middleware logRequest: is not declared in package gentest"},
+		{"invalid timeout", `
+type Params struct{}
+
+// apigen:api {"url": "/sleep", "timeout": "soon"}
+func (api *Api) Sleep(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, `invalid timeout "soon", expected positive duration`},
+		{"zero timeout", `
+type Params struct{}
+
+// apigen:api {"url": "/sleep", "timeout": "0s"}
+func (api *Api) Sleep(ctx context.Context, in Params) (*Params, error) { return &in, nil }
+`, `invalid timeout "0s", expected positive duration`},
+	}
+
+	for _, item := range cases {
+		err := generateSource(t, item.Source)
+		switch {
+		case item.Error == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", item.Name, err)
+		case item.Error != "" && (err == nil || !strings.Contains(err.Error(), item.Error)):
+			t.Errorf("%s: expected error with %q, got %v", item.Name, item.Error, err)
+		}
+	}
+}
+
+// Генерирует обработчики для пакета из source во временной директории внутри модуля, чтобы импорты
+// разрешались так же, как для fixture. В source уже объявлены Api и импортированы context, net/http и apigen,
+// теги полей записываются в одинарных кавычках: ' заменяется на обратную кавычку
+func generateSource(t *testing.T, source string) error {
+	dir, err := ioutil.TempDir("..", "gentest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	header := `package gentest
+
+import (
+	"context"
+	"net/http"
+
+	apigen "` + defaultRuntimeImport + `"
+)
+
+var _ = apigen.Version
+var _ = http.StatusOK
+
+type Api struct{}
+`
+	if err = ioutil.WriteFile(filepath.Join(dir, "api.go"), []byte(header+strings.Replace(source, "'", "`", -1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = generate(dir, filepath.Join(dir, "handlers.go"))
+	return err
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
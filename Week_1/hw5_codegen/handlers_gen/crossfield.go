@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Правило из тега, сравнивающее поле с другим полем той же структуры:
+// gtfield=StartAge, required_if=Status:admin, excluded_with=Name
+type crossFieldTag struct {
+	Rule  string
+	Field string
+	// Значение для required_if
+	Value string
+}
+
+// Операторы сравнения: при каком результате Less(a, b) правило нарушено
+var crossFieldCompare = map[string]struct {
+	// true - сравниваем Less(поле, другое), false - Less(другое, поле)
+	SelfFirst bool
+	// Нарушено, если Less вернул это значение
+	Fails bool
+	Op    string
+}{
+	"gtfield":  {false, false, ">"},
+	"gtefield": {true, true, ">="},
+	"ltfield":  {true, false, "<"},
+	"ltefield": {false, true, "<="},
+}
+
+func parseCrossFieldTag(key, value string) (crossFieldTag, error) {
+	res := crossFieldTag{Rule: key, Field: value}
+	if key == "required_if" {
+		kv := strings.SplitN(value, ":", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return res, fmt.Errorf("invalid required_if: %s, expected required_if=Field:value", value)
+		}
+		res.Field, res.Value = kv[0], kv[1]
+	}
+	return res, nil
+}
+
+// Проверка в сгенерированном коде: если Cond истинно, параметр Param не прошёл правило
+type crossFieldRule struct {
+	Rule    string
+	Param   string
+	Other   string
+	Cond    string
+	Message string
+}
+
+// Собирает правила сравнения полей структуры res. Поля ищутся среди полей той же
+// (в том числе вложенной) структуры, в которой объявлено поле с правилом
+func buildCrossFieldRules(res *dataStruct) ([]*crossFieldRule, error) {
+	rules := make([]*crossFieldRule, 0)
+
+	for _, p := range *res.Params {
+		for _, tag := range p.Validator.CrossFields {
+			otherPath := tag.Field
+			if index := strings.LastIndex(p.Path, "."); index != -1 {
+				otherPath = p.Path[:index+1] + tag.Field
+			}
+
+			var other *structParam
+			for _, candidate := range *res.Params {
+				if candidate.Path == otherPath {
+					other = candidate
+				}
+			}
+			if other == nil || other == p {
+				return nil, fmt.Errorf("%s: %s=%s: field %s with apivalidator tag is not found", p.Path, tag.Rule, tag.Field, tag.Field)
+			}
+
+			rule, err := newCrossFieldRule(tag, p, other)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", p.Path, err)
+			}
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, nil
+}
+
+func newCrossFieldRule(tag crossFieldTag, p, other *structParam) (*crossFieldRule, error) {
+	rule := &crossFieldRule{Rule: tag.Rule, Param: p.ParamName, Other: other.ParamName}
+
+	switch tag.Rule {
+	case "required_if":
+		if other.IsSlice || other.Type == Time || other.Type == Duration {
+			return nil, fmt.Errorf("required_if: can't compare %s field %s with value", describeFieldType(other), tag.Field)
+		}
+		if err := other.Type.checkValue(tag.Value); err != nil {
+			return nil, fmt.Errorf("required_if: %v", err)
+		}
+		guard, value := paramValueExpr(other)
+		rule.Cond = guard + value + " == " + typedLiteral(other.Type, tag.Value) + " && !" + paramPresentExpr(p)
+		rule.Message = p.ParamName + " is required when " + other.ParamName + " is " + tag.Value
+
+	case "excluded_with":
+		rule.Cond = paramPresentExpr(p) + " && " + paramPresentExpr(other)
+		rule.Message = p.ParamName + " must be empty when " + other.ParamName + " is set"
+
+	default:
+		compare := crossFieldCompare[tag.Rule]
+		if p.IsSlice || other.IsSlice || p.Type != other.Type || p.Type == Bool {
+			return nil, fmt.Errorf("%s: can't compare %s with %s field %s",
+				tag.Rule, describeFieldType(p), describeFieldType(other), tag.Field)
+		}
+
+		selfGuard, self := paramValueExpr(p)
+		otherGuard, otherValue := paramValueExpr(other)
+		less := p.Type.Less(otherValue, self)
+		if compare.SelfFirst {
+			less = p.Type.Less(self, otherValue)
+		}
+		if !compare.Fails {
+			less = "!(" + less + ")"
+		}
+		rule.Cond = selfGuard + otherGuard + less
+		rule.Message = p.ParamName + " must be " + compare.Op + " " + other.ParamName
+	}
+
+	return rule, nil
+}
+
+func describeFieldType(p *structParam) string {
+	if p.IsSlice {
+		return "[]" + p.Type.GoType()
+	}
+	return p.Type.GoType()
+}
+
+// Разобранное значение параметра и условие, при котором оно есть (для *T)
+func paramValueExpr(p *structParam) (guard, value string) {
+	if p.IsPointer {
+		return "res." + p.Path + " != nil && ", "(*res." + p.Path + ")"
+	}
+	return "", "res." + p.Path
+}
+
+// Пришёл ли параметр в запросе (без учёта default)
+func paramPresentExpr(p *structParam) string {
+	if p.IsSlice {
+		return "(len(params[" + strconv.Quote(p.ParamName) + "]) > 0)"
+	}
+	return "(params.Get(" + strconv.Quote(p.ParamName) + ") != \"\")"
+}
+
+// Значение из тега как литерал Go типа t: числа переписываются, чтобы 08 не стало восьмеричным литералом.
+// Значение уже проверено checkValue
+func typedLiteral(t FieldTypeEnum, value string) string {
+	if t == Bool {
+		b, _ := strconv.ParseBool(value)
+		return strconv.FormatBool(b)
+	}
+	return goLiteral(t, value)
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Тип поля структуры с тегом apivalidator
+type FieldTypeEnum int
+
+const (
+	Int FieldTypeEnum = iota
+	String
+	Int64
+	Uint64
+	Float64
+	Bool
+	Time
+	Duration
+)
+
+var fieldTypesByName = map[string]FieldTypeEnum{
+	"int":           Int,
+	"string":        String,
+	"int64":         Int64,
+	"uint64":        Uint64,
+	"float64":       Float64,
+	"bool":          Bool,
+	"time.Time":     Time,
+	"time.Duration": Duration,
+}
+
+// Имя типа в сгенерированном коде
+func (t FieldTypeEnum) GoType() string {
+	for name, fieldType := range fieldTypesByName {
+		if fieldType == t {
+			return name
+		}
+	}
+	return ""
+}
+
+// Название типа в сообщении об ошибке: "age must be int"
+func (t FieldTypeEnum) Title() string {
+	switch t {
+	case Time:
+		return "RFC3339 time"
+	case Duration:
+		return "duration"
+	default:
+		return t.GoType()
+	}
+}
+
+// Выражение, разбирающее строку arg в значение типа, вместе с ошибкой
+func (t FieldTypeEnum) Parse(arg string) string {
+	switch t {
+	case Int:
+		return "strconv.Atoi(" + arg + ")"
+	case Int64:
+		return "strconv.ParseInt(" + arg + ", 10, 64)"
+	case Uint64:
+		return "strconv.ParseUint(" + arg + ", 10, 64)"
+	case Float64:
+		return "strconv.ParseFloat(" + arg + ", 64)"
+	case Bool:
+		return "strconv.ParseBool(" + arg + ")"
+	case Time:
+		return "time.Parse(time.RFC3339, " + arg + ")"
+	case Duration:
+		return "time.ParseDuration(" + arg + ")"
+	default:
+		return ""
+	}
+}
+
+// Условие, при котором разобранное без ошибки значение arg всё равно не подходит типу.
+// ParseFloat принимает "NaN" и "Inf", а с ними не работают сравнения min и max
+func (t FieldTypeEnum) Invalid(arg string) string {
+	if t == Float64 {
+		return "math.IsNaN(" + arg + ") || math.IsInf(" + arg + ", 0)"
+	}
+	return ""
+}
+
+// Пакеты, которые нужны выражениям из Parse и Invalid
+func (t FieldTypeEnum) ParseImports() []string {
+	switch t {
+	case String:
+		return nil
+	case Time, Duration:
+		return []string{"time"}
+	case Float64:
+		return []string{"math", "strconv"}
+	default:
+		return []string{"strconv"}
+	}
+}
+
+// Выражение, превращающее значение arg обратно в строку параметра запроса
+func (t FieldTypeEnum) Format(arg string) string {
+	if strings.HasPrefix(arg, "*") && (t == Time || t == Duration) {
+		arg = "(" + arg + ")"
+	}
+	switch t {
+	case Int:
+		return "strconv.Itoa(" + arg + ")"
+	case Int64:
+		return "strconv.FormatInt(" + arg + ", 10)"
+	case Uint64:
+		return "strconv.FormatUint(" + arg + ", 10)"
+	case Float64:
+		return "strconv.FormatFloat(" + arg + ", 'f', -1, 64)"
+	case Bool:
+		return "strconv.FormatBool(" + arg + ")"
+	case Time:
+		return arg + ".Format(time.RFC3339)"
+	case Duration:
+		return arg + ".String()"
+	default:
+		return arg
+	}
+}
+
+// Пакет, который нужен выражению из Format
+func (t FieldTypeEnum) FormatImport() string {
+	switch t {
+	case String:
+		return ""
+	case Time:
+		return "time"
+	case Duration:
+		return ""
+	default:
+		return "strconv"
+	}
+}
+
+// Сравнение a < b для типов, у которых есть естественный порядок
+func (t FieldTypeEnum) Less(a, b string) string {
+	if t == Time {
+		return a + ".Before(" + b + ")"
+	}
+	return a + " < " + b
+}
+
+// Сравнение a > b
+func (t FieldTypeEnum) Greater(a, b string) string {
+	if t == Time {
+		return a + ".After(" + b + ")"
+	}
+	return a + " > " + b
+}
+
+// Поддерживаются ли min/max: у типа есть естественный порядок
+func (t FieldTypeEnum) Ordered() bool {
+	return t != Bool
+}
+
+// Разбирает значение из тега так же, как сгенерированный код разберёт значение параметра
+func (t FieldTypeEnum) checkValue(value string) error {
+	var err error
+	switch t {
+	case Int:
+		_, err = strconv.Atoi(value)
+	case Int64:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case Uint64:
+		_, err = strconv.ParseUint(value, 10, 64)
+	case Float64:
+		if !isFiniteFloat(value) {
+			err = fmt.Errorf("not a finite number")
+		}
+	case Bool:
+		_, err = strconv.ParseBool(value)
+	case Time:
+		_, err = time.Parse(time.RFC3339, value)
+	case Duration:
+		_, err = time.ParseDuration(value)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s value: %s", t.GoType(), value)
+	}
+	return nil
+}
+
+// Разбирает тип поля: T, *T или []T, где T - один из fieldTypesByName
+func parseFieldType(expr ast.Expr) (fieldType FieldTypeEnum, isSlice, isPointer bool, err error) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		isPointer = true
+		expr = t.X
+	case *ast.ArrayType:
+		if t.Len == nil {
+			isSlice = true
+			expr = t.Elt
+		}
+	}
+
+	var typeName string
+	switch t := expr.(type) {
+	case *ast.Ident:
+		typeName = t.Name
+	case *ast.SelectorExpr:
+		if pkgIdent, ok := t.X.(*ast.Ident); ok {
+			typeName = pkgIdent.Name + "." + t.Sel.Name
+		}
+	}
+
+	var ok bool
+	if fieldType, ok = fieldTypesByName[typeName]; !ok {
+		err = fmt.Errorf("invalid filed type: %s", types.ExprString(expr))
+	}
+	return
+}
+
+// Формат строки из format=...
+type stringFormat struct {
+	// Функция проверки из apigen/runtime
+	Func    string
+	OpenApi string
+}
+
+var stringFormats = map[string]*stringFormat{
+	"email": {"apigen.ValidateFormatEmail", "email"},
+	"uuid":  {"apigen.ValidateFormatUUID", "uuid"},
+	"url":   {"apigen.ValidateFormatURL", "uri"},
+	"ipv4":  {"apigen.ValidateFormatIPv4", "ipv4"},
+}
+
+// Проверяет, что правила тега применимы к типу поля
+func checkApiValidator(validator *apiValidator, fieldType FieldTypeEnum) error {
+	limitType := fieldType
+	if fieldType == String {
+		// для строк min/max ограничивают длину
+		limitType = Int
+	}
+
+	for _, limit := range []string{validator.Min, validator.Max} {
+		if limit == "" {
+			continue
+		}
+		if !fieldType.Ordered() {
+			return fmt.Errorf("min/max are not supported for %s", fieldType.GoType())
+		}
+		if err := limitType.checkValue(limit); err != nil {
+			return err
+		}
+	}
+
+	if len(validator.Enum) > 0 && (fieldType == Bool || fieldType == Time) {
+		return fmt.Errorf("enum is not supported for %s", fieldType.GoType())
+	}
+	for _, value := range validator.Enum {
+		if err := fieldType.checkValue(value); err != nil {
+			return err
+		}
+	}
+
+	if validator.Default != "" {
+		if err := fieldType.checkValue(validator.Default); err != nil {
+			return err
+		}
+	}
+
+	return checkStringRules(validator, fieldType)
+}
+
+// pattern, format, minlen и maxlen - только для строк
+func checkStringRules(validator *apiValidator, fieldType FieldTypeEnum) error {
+	if validator.Pattern == "" && validator.Format == "" && validator.MinLen == "" && validator.MaxLen == "" {
+		return nil
+	}
+	if fieldType != String {
+		return fmt.Errorf("pattern, format, minlen and maxlen are supported only for string")
+	}
+
+	if validator.Pattern != "" {
+		if _, err := regexp.Compile(validator.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %v", err)
+		}
+	}
+
+	if validator.Format != "" && stringFormats[validator.Format] == nil {
+		return fmt.Errorf("unknown format %s, expected email, uuid, url or ipv4", validator.Format)
+	}
+
+	if (validator.MinLen != "" && validator.Min != "") || (validator.MaxLen != "" && validator.Max != "") {
+		return fmt.Errorf("min/max and minlen/maxlen can't be used together")
+	}
+	for _, limit := range []string{validator.MinLen, validator.MaxLen} {
+		if limit == "" {
+			continue
+		}
+		if value, err := strconv.Atoi(limit); err != nil || value < 0 {
+			return fmt.Errorf("invalid length: %s", limit)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+)
+
+// Пользовательская проверка из apivalidator:"validate=checkLogin":
+// функция пакета или метод структуры обработчика, func([ctx context.Context, ]value T) error
+type validateHook struct {
+	Name string
+	// Метод структуры обработчика, а не функция пакета
+	IsMethod    bool
+	WithContext bool
+	// Тип значения в сгенерированном коде
+	ValueType string
+}
+
+// Вызов проверки в сгенерированном validateAndBuild: для методов обработчик приходит в h
+func (hook *validateHook) Call() string {
+	if !hook.IsMethod {
+		return hook.Name
+	}
+	args := hook.ValueType
+	if hook.WithContext {
+		args = "context.Context, " + args
+	}
+	return "h.(interface{ " + hook.Name + "(" + args + ") error })." + hook.Name
+}
+
+// Находит проверки validate=... для параметров структуры s, которую принимает метод обработчика objectName.
+// Одна структура может быть у методов разных обработчиков, тогда метод-проверка нужен каждому из них
+func (ctx *parseContext) resolveValidateHooks(s *dataStruct, objectName string) error {
+	for _, p := range *s.Params {
+		if p.Validator.Validate == "" {
+			continue
+		}
+
+		hook, err := ctx.findValidateHook(p, objectName)
+		if err != nil {
+			return fmt.Errorf("%s: validate=%s: %v", p.Path, p.Validator.Validate, err)
+		}
+
+		if p.Hook != nil && *p.Hook != *hook {
+			return fmt.Errorf("%s: validate=%s has different signatures in different handlers", p.Path, hook.Name)
+		}
+		p.Hook = hook
+	}
+	return nil
+}
+
+func (ctx *parseContext) findValidateHook(p *structParam, objectName string) (*validateHook, error) {
+	name := p.Validator.Validate
+	var funcNode *ast.FuncDecl
+	var method *ast.FuncDecl
+
+	for _, file := range *ctx.pkg.Files {
+		for _, decl := range file.Ast.Decls {
+			node, ok := decl.(*ast.FuncDecl)
+			if !ok || node.Name.Name != name {
+				continue
+			}
+			if node.Recv == nil {
+				funcNode = node
+			} else if receiverName(node.Recv) == objectName {
+				method = node
+			}
+		}
+	}
+
+	hook := &validateHook{Name: name, ValueType: p.Type.GoType()}
+	switch {
+	case funcNode != nil:
+	case method != nil:
+		funcNode = method
+		hook.IsMethod = true
+	default:
+		return nil, fmt.Errorf("neither function nor method of %s", objectName)
+	}
+
+	args := make([]ast.Expr, 0, 2)
+	for _, field := range funcNode.Type.Params.List {
+		for range field.Names {
+			args = append(args, field.Type)
+		}
+		if len(field.Names) == 0 {
+			args = append(args, field.Type)
+		}
+	}
+
+	results := funcNode.Type.Results
+	if results == nil || len(results.List) != 1 || len(results.List[0].Names) > 1 || types.ExprString(results.List[0].Type) != "error" {
+		return nil, fmt.Errorf("must return error")
+	}
+
+	if len(args) == 2 {
+		if selector, ok := args[0].(*ast.SelectorExpr); !ok || selector.Sel.Name != "Context" {
+			return nil, fmt.Errorf("first argument must be context.Context")
+		}
+		hook.WithContext = true
+		args = args[1:]
+	}
+	if len(args) != 1 || !isFieldType(args[0], p.Type) {
+		return nil, fmt.Errorf("must accept %s value", p.Type.GoType())
+	}
+
+	return hook, nil
+}
+
+// Имя структуры из получателя метода: (srv *MyApi) или (srv MyApi)
+func receiverName(recv *ast.FieldList) string {
+	if len(recv.List) != 1 {
+		return ""
+	}
+	expr := recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// Совпадает ли тип аргумента с типом поля: у time.Time пакет мог быть импортирован под другим именем
+func isFieldType(expr ast.Expr, fieldType FieldTypeEnum) bool {
+	if selector, ok := expr.(*ast.SelectorExpr); ok && (fieldType == Time || fieldType == Duration) {
+		return "time."+selector.Sel.Name == fieldType.GoType()
+	}
+	return types.ExprString(expr) == fieldType.GoType()
+}
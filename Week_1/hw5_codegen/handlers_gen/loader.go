@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+)
+
+// Исходный файл пакета
+type sourceFile struct {
+	Path string
+	Ast  *ast.File
+}
+
+type sourceFiles []*sourceFile
+
+// Пакет, из которого собираем обработчики и структуры
+type sourcePackage struct {
+	Name       string
+	ImportPath string
+	Dir        string
+	Files      *sourceFiles
+	// Если задан - обработчики берём только из этого файла, остальные файлы пакета нужны для типов
+	Target string
+}
+
+// Загружает пакеты и кеширует их по пути импорта
+type packageLoader struct {
+	fSet     *token.FileSet
+	packages map[string]*sourcePackage
+}
+
+func newPackageLoader(fSet *token.FileSet) *packageLoader {
+	return &packageLoader{
+		fSet:     fSet,
+		packages: make(map[string]*sourcePackage),
+	}
+}
+
+// Загружает пакет, для которого генерируем код.
+// target - либо файл (обработчики только из него), либо директория пакета.
+// output исключается из разбора, чтобы не читать собственный результат прошлого запуска
+func (l *packageLoader) loadTarget(target, output string) (*sourcePackage, error) {
+	info, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := target
+	targetFile := ""
+	if !info.IsDir() {
+		dir = filepath.Dir(target)
+		if targetFile, err = filepath.Abs(target); err != nil {
+			return nil, err
+		}
+	}
+
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := ""
+	if output != "" {
+		if excluded, err = filepath.Abs(output); err != nil {
+			return nil, err
+		}
+	}
+
+	pkg, err := l.parseBuildPackage(bp, excluded)
+	if err != nil {
+		return nil, err
+	}
+	pkg.Target = targetFile
+
+	if targetFile != "" && pkg.file(targetFile) == nil {
+		return nil, fmt.Errorf("file %s is not part of package %s", target, pkg.Name)
+	}
+
+	return pkg, nil
+}
+
+// Загружает импортированный пакет, srcDir - директория импортирующего пакета
+func (l *packageLoader) loadImport(importPath, srcDir string) (*sourcePackage, error) {
+	if pkg, ok := l.packages[importPath]; ok {
+		return pkg, nil
+	}
+
+	bp, err := build.Import(importPath, srcDir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg, err := l.parseBuildPackage(bp, "")
+	if err != nil {
+		return nil, err
+	}
+	pkg.ImportPath = importPath
+
+	l.packages[importPath] = pkg
+	return pkg, nil
+}
+
+func (l *packageLoader) parseBuildPackage(bp *build.Package, excluded string) (*sourcePackage, error) {
+	files := sourceFiles(make([]*sourceFile, 0, len(bp.GoFiles)))
+
+	for _, name := range bp.GoFiles {
+		filePath, err := filepath.Abs(filepath.Join(bp.Dir, name))
+		if err != nil {
+			return nil, err
+		}
+		if filePath == excluded {
+			continue
+		}
+
+		root, err := parser.ParseFile(l.fSet, filePath, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &sourceFile{filePath, root})
+	}
+
+	return &sourcePackage{
+		Name:       bp.Name,
+		ImportPath: bp.ImportPath,
+		Dir:        bp.Dir,
+		Files:      &files,
+	}, nil
+}
+
+// Ищет путь импорта, под именем name видимого в файле file
+func (l *packageLoader) resolveImport(file *sourceFile, name, srcDir string) (string, error) {
+	unnamed := make([]string, 0, len(file.Ast.Imports))
+
+	for _, spec := range file.Ast.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return "", err
+		}
+
+		if spec.Name != nil {
+			if spec.Name.Name == name {
+				return importPath, nil
+			}
+			continue
+		}
+
+		// Чаще всего имя пакета совпадает с последним элементом пути, такие проверяем первыми
+		if path.Base(importPath) == name {
+			unnamed = append([]string{importPath}, unnamed...)
+		} else {
+			unnamed = append(unnamed, importPath)
+		}
+	}
+
+	for _, importPath := range unnamed {
+		bp, err := build.Import(importPath, srcDir, 0)
+		if err != nil {
+			continue
+		}
+		if bp.Name == name {
+			return importPath, nil
+		}
+	}
+
+	return "", fmt.Errorf("%s: unknown package %s", file.Path, name)
+}
+
+func (p *sourcePackage) file(filePath string) *sourceFile {
+	for _, f := range *p.Files {
+		if f.Path == filePath {
+			return f
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// Проверяет, что middleware из "middleware": [...] объявлен в пакете: функция func(http.Handler) http.Handler
+// или переменная (её тип проверит компилятор)
+func (ctx *parseContext) checkMiddleware(name string) error {
+	if !token.IsIdentifier(name) {
+		return fmt.Errorf("invalid name")
+	}
+
+	for _, file := range *ctx.pkg.Files {
+		for _, decl := range file.Ast.Decls {
+			switch node := decl.(type) {
+			case *ast.FuncDecl:
+				if node.Recv == nil && node.Name.Name == name {
+					return ctx.checkMiddlewareFunc(node, file)
+				}
+			case *ast.GenDecl:
+				if node.Tok != token.VAR {
+					continue
+				}
+				for _, spec := range node.Specs {
+					for _, ident := range spec.(*ast.ValueSpec).Names {
+						if ident.Name == name {
+							return nil
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return fmt.Errorf("is not declared in package %s", ctx.pkg.Name)
+}
+
+func (ctx *parseContext) checkMiddlewareFunc(node *ast.FuncDecl, file *sourceFile) error {
+	params, results := node.Type.Params.List, node.Type.Results
+	if len(params) != 1 || len(params[0].Names) > 1 || results == nil || len(results.List) != 1 || len(results.List[0].Names) > 1 {
+		return fmt.Errorf("must be func(http.Handler) http.Handler")
+	}
+
+	for _, expr := range []ast.Expr{params[0].Type, results.List[0].Type} {
+		isHandler, err := ctx.isHttpHandler(expr, file)
+		if err != nil {
+			return err
+		}
+		if !isHandler {
+			return fmt.Errorf("must be func(http.Handler) http.Handler")
+		}
+	}
+	return nil
+}
+
+// http.Handler, пакет net/http мог быть импортирован под другим именем
+func (ctx *parseContext) isHttpHandler(expr ast.Expr, file *sourceFile) (bool, error) {
+	selector, ok := expr.(*ast.SelectorExpr)
+	if !ok || selector.Sel.Name != "Handler" {
+		return false, nil
+	}
+	pkgIdent, ok := selector.X.(*ast.Ident)
+	if !ok {
+		return false, nil
+	}
+	importPath, err := ctx.loader.resolveImport(file, pkgIdent.Name, ctx.pkg.Dir)
+	if err != nil {
+		return false, err
+	}
+	return importPath == "net/http", nil
+}
@@ -0,0 +1,668 @@
+package main
+
+import (
+	"encoding/json"
+	"go/ast"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Объект OpenAPI-документа, ключи в выводе encoding/json сортирует сам
+type jsonObject map[string]interface{}
+
+// Собирает OpenAPI-документ для одной структуры обработчика
+type openApiBuilder struct {
+	ctx *parseContext
+	// components.schemas - структуры из ответов методов
+	schemas jsonObject
+	hasAuth bool
+	specs   *HandlerSpecs
+}
+
+// Пишет OpenAPI 3 документ. Если структур обработчиков несколько, у каждой свой файл:
+// openapi.json -> openapi_MyApi.json, openapi_OtherApi.json
+func generateOpenApi(data *generatorData, output string) error {
+	handlers := data.Handlers.sorted()
+
+	for _, handler := range handlers {
+		content, err := openApiDocument(data, handler)
+		if err != nil {
+			return err
+		}
+
+		path := output
+		if len(handlers) > 1 {
+			ext := filepath.Ext(output)
+			path = strings.TrimSuffix(output, ext) + "_" + handler.Name + ext
+		}
+
+		if err = writeFile(path, content); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Документ структуры обработчика в том виде, в котором он пишется в файл
+func openApiDocument(data *generatorData, handler *handlerObject) ([]byte, error) {
+	doc, err := buildOpenApi(data.ctx, handler)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+func buildOpenApi(ctx *parseContext, handler *handlerObject) (jsonObject, error) {
+	b := &openApiBuilder{
+		ctx:   ctx,
+		specs: handler.Specs,
+		schemas: jsonObject{
+			"ErrorResponse": jsonObject{
+				"type": "object",
+				"properties": jsonObject{
+					"error": jsonObject{"type": "string"},
+					// Только для методов с "errors": "all"
+					"errors": jsonObject{
+						"type":  "array",
+						"items": jsonObject{"$ref": "#/components/schemas/FieldError"},
+					},
+					// Если клиент прислал X-Request-ID или traceparent
+					"request_id": jsonObject{"type": "string"},
+				},
+				"required": []string{"error"},
+			},
+			"FieldError": jsonObject{
+				"type": "object",
+				"properties": jsonObject{
+					"param":   jsonObject{"type": "string"},
+					"rule":    jsonObject{"type": "string"},
+					"message": jsonObject{"type": "string"},
+				},
+				"required": []string{"param", "rule", "message"},
+			},
+		},
+	}
+
+	if handler.Specs.Encoder == "problem" {
+		b.schemas["Problem"] = jsonObject{
+			"type": "object",
+			"properties": jsonObject{
+				"type":     jsonObject{"type": "string"},
+				"title":    jsonObject{"type": "string"},
+				"status":   jsonObject{"type": "integer"},
+				"detail":   jsonObject{"type": "string"},
+				"instance": jsonObject{"type": "string"},
+				// Только для методов с "errors": "all"
+				"errors": jsonObject{
+					"type":  "array",
+					"items": jsonObject{"$ref": "#/components/schemas/FieldError"},
+				},
+				"request_id": jsonObject{"type": "string"},
+			},
+			"required": []string{"type", "title", "status"},
+		}
+		delete(b.schemas, "ErrorResponse")
+	}
+
+	paths := jsonObject{}
+	for _, method := range handler.Methods.sorted() {
+		path := openApiPath(method)
+		pathItem, ok := paths[path].(jsonObject)
+		if !ok {
+			pathItem = jsonObject{}
+			paths[path] = pathItem
+		}
+
+		operations, err := b.operations(method)
+		if err != nil {
+			return nil, err
+		}
+		for httpMethod, operation := range operations {
+			pathItem[httpMethod] = operation
+		}
+	}
+
+	components := jsonObject{"schemas": b.schemas}
+	if b.hasAuth {
+		// Так проверяет DefaultAuthenticator
+		components["securitySchemes"] = jsonObject{
+			"XAuth": jsonObject{"type": "apiKey", "in": "header", "name": "X-Auth"},
+		}
+	}
+
+	return jsonObject{
+		"openapi":    "3.0.3",
+		"info":       jsonObject{"title": handler.Name, "version": "1.0.0"},
+		"paths":      paths,
+		"components": components,
+	}, nil
+}
+
+// /user/{id:int} -> /user/{id}
+func openApiPath(method *handlerMethod) string {
+	if method.Route == nil {
+		return method.Specs.Url
+	}
+
+	path := method.Specs.Url
+	for _, param := range method.Route.Params {
+		path = strings.Replace(path, "{"+param.Name+":"+param.Type+"}", "{"+param.Name+"}", 1)
+	}
+	return path
+}
+
+func (b *openApiBuilder) operations(method *handlerMethod) (jsonObject, error) {
+	// Метод без ограничения принимает и GET, и POST. HEAD и OPTIONS обрабатываются автоматически и не описываются
+	httpMethods := []string{"get", "post"}
+	if len(method.Specs.Method) > 0 {
+		httpMethods = make([]string, 0, len(method.Specs.Method))
+		for _, httpMethod := range method.Specs.Method {
+			httpMethods = append(httpMethods, strings.ToLower(httpMethod))
+		}
+	}
+
+	inPath := make(map[string]*routeParam)
+	if method.Route != nil {
+		for _, param := range method.Route.Params {
+			inPath[param.Name] = param
+		}
+	}
+
+	responses, err := b.responses(method)
+	if err != nil {
+		return nil, err
+	}
+
+	res := jsonObject{}
+	for _, httpMethod := range httpMethods {
+		operationId := method.Name
+		if len(httpMethods) > 1 {
+			operationId += strings.Title(httpMethod)
+		}
+
+		hasBody := httpMethod == "post" || httpMethod == "put" || httpMethod == "patch"
+		parameters := make([]jsonObject, 0)
+		bodyParams := make([]*structParam, 0)
+
+		for _, s := range method.Params {
+			for _, p := range *s.Params {
+				if routeParam, ok := inPath[p.ParamName]; ok {
+					schema := paramSchema(p)
+					if routeParam.Type != "string" && p.Type == String {
+						schema["type"] = "integer"
+					}
+					parameters = append(parameters, jsonObject{
+						"name": p.ParamName, "in": "path", "required": true, "schema": schema,
+					})
+					continue
+				}
+
+				if hasBody {
+					bodyParams = append(bodyParams, p)
+					continue
+				}
+
+				parameters = append(parameters, jsonObject{
+					"name": p.ParamName, "in": "query", "required": paramRequired(p), "schema": paramSchema(p),
+				})
+			}
+		}
+
+		operationResponses := responses
+		if method.Specs.Cache != nil && httpMethod == "get" {
+			operationResponses = cachedResponses(responses)
+			parameters = append(parameters, jsonObject{
+				"name": "If-None-Match", "in": "header", "required": false, "schema": jsonObject{"type": "string"},
+			})
+		}
+
+		operation := jsonObject{
+			"operationId": operationId,
+			"responses":   operationResponses,
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+		if hasBody && len(bodyParams) > 0 {
+			operation["requestBody"] = jsonObject{
+				"content": jsonObject{
+					"application/x-www-form-urlencoded": jsonObject{"schema": bodySchema(bodyParams, false)},
+					"application/json":                  jsonObject{"schema": bodySchema(bodyParams, true)},
+				},
+			}
+		}
+		if method.Specs.Auth {
+			b.hasAuth = true
+			operation["security"] = []jsonObject{{"XAuth": []string{}}}
+			if len(method.Specs.Roles) > 0 {
+				operation["description"] = "Required roles (any of): " + strings.Join(method.Specs.Roles, ", ")
+			}
+		}
+
+		res[httpMethod] = operation
+	}
+
+	return res, nil
+}
+
+func (b *openApiBuilder) responses(method *handlerMethod) (jsonObject, error) {
+	envelope := jsonObject{
+		"type":       "object",
+		"properties": jsonObject{"error": jsonObject{"type": "string"}},
+		"required":   []string{"error"},
+	}
+
+	var result jsonObject
+	if method.Result != nil {
+		schema, err := b.typeSchema(method.Result, method.File, b.ctx.pkg)
+		if err != nil {
+			return nil, err
+		}
+		envelope["properties"].(jsonObject)["response"] = schema
+		result = schema
+	}
+	if b.specs.Envelope() {
+		result = envelope
+	}
+
+	// Встроенные Encoder отвечают в формате из Accept: JSON, XML или MessagePack
+	errorSchema := jsonObject{"$ref": "#/components/schemas/ErrorResponse"}
+	errorTypes := []string{"application/json", "application/xml", "application/msgpack"}
+	if b.specs.Encoder == "problem" {
+		errorSchema = jsonObject{"$ref": "#/components/schemas/Problem"}
+		errorTypes = []string{"application/problem+json", "application/problem+xml", "application/msgpack"}
+	}
+	errorResponse := func(description string) jsonObject {
+		return jsonObject{"description": description, "content": mediaContent(errorSchema, errorTypes)}
+	}
+
+	ok := jsonObject{"description": "OK"}
+	if result != nil {
+		ok["content"] = mediaContent(result, []string{"application/json", "application/xml", "application/msgpack"})
+	}
+
+	res := jsonObject{
+		"200": ok,
+		"400": errorResponse("Invalid params"),
+		"500": errorResponse("Internal error"),
+	}
+	res["406"] = errorResponse("Not acceptable")
+	if len(method.Specs.Method) > 0 {
+		if status := strconv.Itoa(b.specs.BadMethodStatus()); status == "406" {
+			res[status] = errorResponse("Bad method or not acceptable")
+		} else {
+			res[status] = errorResponse("Method not allowed")
+		}
+	}
+	if len(method.Specs.Method) == 0 || contains(method.Specs.Method, http.MethodPost) ||
+		contains(method.Specs.Method, http.MethodPut) || contains(method.Specs.Method, http.MethodPatch) {
+		res["413"] = errorResponse("Request body too large")
+	}
+	if method.Specs.Auth {
+		res["403"] = errorResponse("Unauthorized")
+	}
+	if method.Specs.Timeout != "" {
+		res["504"] = errorResponse("Timeout")
+	}
+	if method.Specs.RateLimit != nil {
+		tooMany := errorResponse("Too many requests")
+		tooMany["headers"] = jsonObject{
+			"Retry-After": jsonObject{
+				"description": "Seconds to wait before retrying",
+				"schema":      jsonObject{"type": "integer"},
+			},
+		}
+		res["429"] = tooMany
+	}
+
+	return res, nil
+}
+
+// Ответы GET с "cache": ETag и Cache-Control у 200 и 304 на If-None-Match
+func cachedResponses(responses jsonObject) jsonObject {
+	res := jsonObject{}
+	for status, response := range responses {
+		res[status] = response
+	}
+	ok := jsonObject{}
+	for key, value := range responses["200"].(jsonObject) {
+		ok[key] = value
+	}
+	ok["headers"] = jsonObject{
+		"ETag":          jsonObject{"schema": jsonObject{"type": "string"}},
+		"Cache-Control": jsonObject{"schema": jsonObject{"type": "string"}},
+	}
+	res["200"] = ok
+	res["304"] = jsonObject{"description": "Not modified"}
+	return res
+}
+
+func mediaContent(schema jsonObject, mediaTypes []string) jsonObject {
+	res := jsonObject{}
+	for _, mediaType := range mediaTypes {
+		// в XML имена берутся из xml-тегов и полей Go, а не из json-тегов схемы, поэтому схемы у XML нет
+		if strings.HasSuffix(mediaType, "xml") {
+			res[mediaType] = jsonObject{}
+			continue
+		}
+		res[mediaType] = jsonObject{"schema": schema}
+	}
+	return res
+}
+
+// Без параметра запрос не пройдёт проверку: required или пустое значение не разбирается в тип поля
+func paramRequired(p *structParam) bool {
+	if p.Validator.Required {
+		return true
+	}
+	return !p.IsPointer && !p.IsSlice && p.Type != String && p.Validator.Default == ""
+}
+
+// Схема параметра запроса со всеми правилами apivalidator
+func paramSchema(p *structParam) jsonObject {
+	schema := scalarSchema(p.Type)
+	v := p.Validator
+
+	if len(v.Enum) > 0 {
+		enum := make([]interface{}, 0, len(v.Enum))
+		for _, value := range v.Enum {
+			enum = append(enum, typedValue(p.Type, value))
+		}
+		schema["enum"] = enum
+	}
+
+	minKey, maxKey := "minimum", "maximum"
+	if p.Type == String {
+		minKey, maxKey = "minLength", "maxLength"
+	}
+	if p.Type != Time && p.Type != Duration {
+		if v.Min != "" {
+			schema[minKey] = typedValue(Float64, v.Min)
+		}
+		if v.Max != "" {
+			schema[maxKey] = typedValue(Float64, v.Max)
+		}
+	}
+
+	if v.MinLen != "" {
+		schema["minLength"] = typedValue(Float64, v.MinLen)
+	}
+	if v.MaxLen != "" {
+		schema["maxLength"] = typedValue(Float64, v.MaxLen)
+	}
+	if v.Pattern != "" {
+		schema["pattern"] = v.Pattern
+	}
+	if format := stringFormats[v.Format]; format != nil {
+		schema["format"] = format.OpenApi
+	}
+
+	if !p.IsSlice {
+		if v.Default != "" {
+			schema["default"] = typedValue(p.Type, v.Default)
+		}
+		return schema
+	}
+
+	res := jsonObject{"type": "array", "items": schema}
+	if v.Default != "" {
+		res["default"] = []interface{}{typedValue(p.Type, v.Default)}
+	}
+	return res
+}
+
+func scalarSchema(t FieldTypeEnum) jsonObject {
+	switch t {
+	case Int:
+		return jsonObject{"type": "integer"}
+	case Int64:
+		return jsonObject{"type": "integer", "format": "int64"}
+	case Uint64:
+		return jsonObject{"type": "integer", "format": "int64", "minimum": 0}
+	case Float64:
+		return jsonObject{"type": "number", "format": "double"}
+	case Bool:
+		return jsonObject{"type": "boolean"}
+	case Time:
+		return jsonObject{"type": "string", "format": "date-time"}
+	case Duration:
+		return jsonObject{"type": "string", "format": "duration", "example": "1s"}
+	default:
+		return jsonObject{"type": "string"}
+	}
+}
+
+// Значение из тега в виде JSON-значения нужного типа
+func typedValue(t FieldTypeEnum, value string) interface{} {
+	switch t {
+	case Int, Int64:
+		if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return v
+		}
+	case Uint64:
+		if v, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return v
+		}
+	case Float64:
+		if v, err := strconv.ParseFloat(value, 64); err == nil {
+			return v
+		}
+	case Bool:
+		if v, err := strconv.ParseBool(value); err == nil {
+			return v
+		}
+	}
+	return value
+}
+
+// Тело запроса: для формы имена параметров как есть (filter.name), для JSON - вложенные объекты
+func bodySchema(params []*structParam, nested bool) jsonObject {
+	res := jsonObject{"type": "object", "properties": jsonObject{}}
+
+	for _, p := range params {
+		path := []string{p.ParamName}
+		if nested {
+			path = strings.Split(p.ParamName, ".")
+		}
+		addProperty(res, path, paramSchema(p), paramRequired(p))
+	}
+
+	return res
+}
+
+func addProperty(obj jsonObject, path []string, schema jsonObject, required bool) {
+	properties := obj["properties"].(jsonObject)
+
+	if len(path) == 1 {
+		properties[path[0]] = schema
+		if required {
+			requiredList, _ := obj["required"].([]string)
+			obj["required"] = append(requiredList, path[0])
+		}
+		return
+	}
+
+	child, ok := properties[path[0]].(jsonObject)
+	if !ok {
+		child = jsonObject{"type": "object", "properties": jsonObject{}}
+		properties[path[0]] = child
+	}
+	addProperty(child, path[1:], schema, required)
+}
+
+var basicTypeSchemas = map[string]jsonObject{
+	"bool":    {"type": "boolean"},
+	"string":  {"type": "string"},
+	"int":     {"type": "integer"},
+	"int8":    {"type": "integer"},
+	"int16":   {"type": "integer"},
+	"int32":   {"type": "integer", "format": "int32"},
+	"int64":   {"type": "integer", "format": "int64"},
+	"uint":    {"type": "integer", "minimum": 0},
+	"uint8":   {"type": "integer", "minimum": 0},
+	"uint16":  {"type": "integer", "minimum": 0},
+	"uint32":  {"type": "integer", "minimum": 0},
+	"uint64":  {"type": "integer", "format": "int64", "minimum": 0},
+	"float32": {"type": "number", "format": "float"},
+	"float64": {"type": "number", "format": "double"},
+}
+
+// Схема того, во что encoding/json превратит значение типа expr
+func (b *openApiBuilder) typeSchema(expr ast.Expr, file *sourceFile, pkg *sourcePackage) (jsonObject, error) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return b.typeSchema(t.X, file, pkg)
+
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return jsonObject{"type": "string", "format": "byte"}, nil
+		}
+		items, err := b.typeSchema(t.Elt, file, pkg)
+		if err != nil {
+			return nil, err
+		}
+		return jsonObject{"type": "array", "items": items}, nil
+
+	case *ast.MapType:
+		values, err := b.typeSchema(t.Value, file, pkg)
+		if err != nil {
+			return nil, err
+		}
+		return jsonObject{"type": "object", "additionalProperties": values}, nil
+
+	case *ast.InterfaceType:
+		return jsonObject{}, nil
+
+	case *ast.StructType:
+		return b.structSchema(t, file, pkg)
+
+	case *ast.Ident:
+		if schema, ok := basicTypeSchemas[t.Name]; ok {
+			res := jsonObject{}
+			for key, value := range schema {
+				res[key] = value
+			}
+			return res, nil
+		}
+
+	case *ast.SelectorExpr:
+		if pkgIdent, ok := t.X.(*ast.Ident); ok {
+			switch pkgIdent.Name + "." + t.Sel.Name {
+			case "time.Time":
+				return jsonObject{"type": "string", "format": "date-time"}, nil
+			case "time.Duration":
+				return jsonObject{"type": "integer", "format": "int64"}, nil
+			case "json.RawMessage":
+				return jsonObject{}, nil
+			}
+		}
+	}
+
+	s, err := b.ctx.findStruct(expr, file, pkg)
+	if err != nil {
+		// Именованный тип, который не структура: о нём ничего не известно
+		return jsonObject{}, nil
+	}
+
+	name := s.Name
+	if s.Package.Dir != b.ctx.pkg.Dir {
+		name = s.Package.Name + "." + s.Name
+	}
+	ref := jsonObject{"$ref": "#/components/schemas/" + name}
+
+	if _, ok := b.schemas[name]; ok {
+		return ref, nil
+	}
+	// Заглушка на случай структуры, ссылающейся на саму себя
+	b.schemas[name] = jsonObject{}
+
+	schema, err := b.structSchema(s.Decl, s.File, s.Package)
+	if err != nil {
+		return nil, err
+	}
+	b.schemas[name] = schema
+
+	return ref, nil
+}
+
+func (b *openApiBuilder) structSchema(decl *ast.StructType, file *sourceFile, pkg *sourcePackage) (jsonObject, error) {
+	res := jsonObject{"type": "object", "properties": jsonObject{}}
+	properties := res["properties"].(jsonObject)
+	required := make([]string, 0)
+
+	for _, field := range decl.Fields.List {
+		jsonName, omitEmpty := "", false
+		if field.Tag != nil {
+			tag := reflect.StructTag(field.Tag.Value[1 : len(field.Tag.Value)-1])
+			options := strings.Split(tag.Get("json"), ",")
+			if options[0] == "-" && len(options) == 1 {
+				continue
+			}
+			jsonName = options[0]
+			for _, option := range options[1:] {
+				omitEmpty = omitEmpty || option == "omitempty"
+			}
+		}
+
+		names := make([]string, 0, len(field.Names))
+		for _, name := range field.Names {
+			if ast.IsExported(name.Name) {
+				names = append(names, name.Name)
+			}
+		}
+
+		// Поля встроенной структуры без имени в json-теге encoding/json поднимает на уровень выше
+		if len(field.Names) == 0 {
+			fieldType := field.Type
+			if star, ok := fieldType.(*ast.StarExpr); ok {
+				fieldType = star.X
+			}
+			if embedded, err := b.ctx.findStruct(fieldType, file, pkg); err == nil && jsonName == "" {
+				schema, err := b.structSchema(embedded.Decl, embedded.File, embedded.Package)
+				if err != nil {
+					return nil, err
+				}
+				for key, value := range schema["properties"].(jsonObject) {
+					properties[key] = value
+				}
+				if embeddedRequired, ok := schema["required"].([]string); ok {
+					required = append(required, embeddedRequired...)
+				}
+				continue
+			}
+			if name := embeddedFieldName(field.Type); ast.IsExported(name) {
+				names = append(names, name)
+			}
+		}
+
+		for _, name := range names {
+			schema, err := b.typeSchema(field.Type, file, pkg)
+			if err != nil {
+				return nil, err
+			}
+
+			propertyName := name
+			if jsonName != "" {
+				propertyName = jsonName
+			}
+			properties[propertyName] = schema
+			if !omitEmpty {
+				required = append(required, propertyName)
+			}
+		}
+	}
+
+	if len(required) > 0 {
+		res["required"] = required
+	}
+	return res, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+// testdata/openapi_*.json - документы для api.go, обновляются так же, как пишутся:
+//
+//	go build handlers_gen/* && ./codegen -openapi testdata/openapi.json api.go api_handlers.go
+func TestOpenApiGolden(t *testing.T) {
+	data, _, err := generate("../api.go", "../api_handlers.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handlers := data.Handlers.sorted()
+	if len(handlers) != 2 {
+		t.Fatalf("expected MyApi and OtherApi, got %d handlers", len(handlers))
+	}
+	for _, handler := range handlers {
+		content, err := openApiDocument(data, handler)
+		if err != nil {
+			t.Errorf("%s: %v", handler.Name, err)
+			continue
+		}
+		golden := "../testdata/openapi_" + handler.Name + ".json"
+		expected, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Errorf("%s: %v", handler.Name, err)
+			continue
+		}
+		if !bytes.Equal(content, expected) {
+			t.Errorf("%s: document differs from %s:\n%s", handler.Name, golden, content)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+)
+
+// Состояние разбора пакета
+type parseContext struct {
+	loader *packageLoader
+	pkg    *sourcePackage
+	// Структуры пакетов по директории пакета
+	structs map[string]*dataStructs
+	data    *generatorData
+}
+
+func (ctx *parseContext) packageStructs(pkg *sourcePackage) (*dataStructs, error) {
+	if structs, ok := ctx.structs[pkg.Dir]; ok {
+		return structs, nil
+	}
+
+	structs, err := parseDataStructs(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.structs[pkg.Dir] = structs
+	return structs, nil
+}
+
+// Находит объявление структуры по типу из файла file пакета pkg: Pagination или dto.Pagination
+func (ctx *parseContext) findStruct(expr ast.Expr, file *sourceFile, pkg *sourcePackage) (*dataStruct, error) {
+	var name string
+	switch t := expr.(type) {
+	case *ast.Ident:
+		name = t.Name
+
+	case *ast.SelectorExpr:
+		pkgIdent, ok := t.X.(*ast.Ident)
+		if !ok {
+			return nil, fmt.Errorf("unsupported type: %s", types.ExprString(expr))
+		}
+
+		importPath, err := ctx.loader.resolveImport(file, pkgIdent.Name, pkg.Dir)
+		if err != nil {
+			return nil, err
+		}
+
+		if pkg, err = ctx.loader.loadImport(importPath, pkg.Dir); err != nil {
+			return nil, err
+		}
+		name = t.Sel.Name
+
+	default:
+		return nil, fmt.Errorf("unsupported type: %s", types.ExprString(expr))
+	}
+
+	structs, err := ctx.packageStructs(pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := (*structs)[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown struct: %s", types.ExprString(expr))
+	}
+	return s, nil
+}
+
+// Находит структуру параметров метода: ProfileParams или dto.ProfileParams
+func (ctx *parseContext) resolveParamStruct(expr ast.Expr, file *sourceFile) (*dataStruct, error) {
+	s, err := ctx.findStruct(expr, file, ctx.pkg)
+	if err != nil {
+		return nil, fmt.Errorf("unknown params struct: %s", types.ExprString(expr))
+	}
+
+	res := &dataStruct{
+		Name:    s.Name,
+		Ident:   s.Name,
+		Fields:  s.Fields,
+		Decl:    s.Decl,
+		File:    s.File,
+		Package: s.Package,
+	}
+
+	if selector, ok := expr.(*ast.SelectorExpr); ok {
+		alias := selector.X.(*ast.Ident).Name
+		if err = ctx.data.Imports.add(alias, s.Package.ImportPath); err != nil {
+			return nil, err
+		}
+		res.Name = alias + "." + s.Name
+		res.Ident = strings.Title(alias) + s.Name
+	}
+
+	if existing, ok := (*ctx.data.Structs)[res.Name]; ok {
+		return existing, nil
+	}
+
+	params := structParams(make([]*structParam, 0, len(*s.Fields)))
+	res.Params = &params
+	res.Allocs = make([]*structAlloc, 0)
+
+	if err = ctx.flatten(res, s, "", "", make(map[*dataStruct]bool)); err != nil {
+		return nil, fmt.Errorf("%s: %v", res.Name, err)
+	}
+
+	if res.CrossRules, err = buildCrossFieldRules(res); err != nil {
+		return nil, fmt.Errorf("%s: %v", res.Name, err)
+	}
+
+	if err = ctx.data.Imports.add("context", "context"); err != nil {
+		return nil, err
+	}
+	for _, p := range *res.Params {
+		for _, importPath := range p.Type.ParseImports() {
+			if err = ctx.data.Imports.add(importPath, importPath); err != nil {
+				return nil, err
+			}
+		}
+		if p.Validator.Pattern != "" {
+			p.PatternVar = "pattern" + res.Ident + p.Var
+			if err = ctx.data.Imports.add("regexp", "regexp"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	buildValueChecks(res)
+
+	(*ctx.data.Structs)[res.Name] = res
+	return res, nil
+}
+
+// Раскладывает поля структуры s (в том числе вложенных и встроенных структур) в параметры res.
+// path - путь до s от res, prefix - префикс имён параметров вложенной структуры
+func (ctx *parseContext) flatten(res, s *dataStruct, path, prefix string, stack map[*dataStruct]bool) error {
+	if stack[s] {
+		return fmt.Errorf("recursive struct %s", s.Name)
+	}
+	stack[s] = true
+	defer delete(stack, s)
+
+	foreign := s.Package.Dir != ctx.pkg.Dir
+
+	for _, field := range *s.Fields {
+		if field.StructType == nil {
+			if foreign && !ast.IsExported(field.Name) {
+				return fmt.Errorf("field %s of %s is not exported", field.Name, s.Name)
+			}
+
+			*res.Params = append(*res.Params, &structParam{
+				dataStructField: field,
+				Path:            path + field.Name,
+				Var:             strings.Replace(path, ".", "", -1) + field.Name,
+				ParamName:       joinParamName(prefix, fieldParamName(field)),
+			})
+			continue
+		}
+
+		if err := ctx.flattenNested(res, s, field, path, prefix, stack); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ctx *parseContext) flattenNested(res, s *dataStruct, field *dataStructField, path, prefix string, stack map[*dataStruct]bool) error {
+	structType := field.StructType
+	star, isPointer := structType.(*ast.StarExpr)
+	if isPointer {
+		structType = star.X
+	}
+
+	nested, err := ctx.findStruct(structType, s.File, s.Package)
+	if err != nil {
+		if field.Validator != nil {
+			return fmt.Errorf("invalid filed type: %s", types.ExprString(field.StructType))
+		}
+		// Обычное поле, к параметрам запроса отношения не имеет
+		return nil
+	}
+
+	if field.Validator != nil {
+		v := field.Validator
+		if v.Required || len(v.Enum) > 0 || v.Default != "" || v.Min != "" || v.Max != "" ||
+			v.MinLen != "" || v.MaxLen != "" || v.Pattern != "" || v.Format != "" || v.Validate != "" ||
+			len(v.CrossFields) > 0 {
+			return fmt.Errorf("%s: only paramname is supported for nested structs", field.Name)
+		}
+	}
+
+	nestedPrefix := prefix
+	if !field.Embedded || field.Validator != nil {
+		nestedPrefix = joinParamName(prefix, fieldParamName(field))
+	}
+
+	paramsCount := len(*res.Params)
+	allocsCount := len(res.Allocs)
+
+	if err = ctx.flatten(res, nested, path+field.Name+".", nestedPrefix, stack); err != nil {
+		return err
+	}
+
+	if len(*res.Params) == paramsCount {
+		res.Allocs = res.Allocs[:allocsCount]
+		if field.Validator != nil {
+			return fmt.Errorf("%s: struct %s has no apivalidator fields", field.Name, nested.Name)
+		}
+		return nil
+	}
+
+	if s.Package.Dir != ctx.pkg.Dir && !ast.IsExported(field.Name) {
+		return fmt.Errorf("field %s of %s is not exported", field.Name, s.Name)
+	}
+
+	if isPointer {
+		typeName, err := ctx.qualify(nested)
+		if err != nil {
+			return err
+		}
+		// Сама структура создаётся раньше вложенных в неё
+		alloc := &structAlloc{path + field.Name, typeName}
+		res.Allocs = append(res.Allocs[:allocsCount], append([]*structAlloc{alloc}, res.Allocs[allocsCount:]...)...)
+	}
+
+	return nil
+}
+
+// Ищет в структуре обработчика поле типа Authenticator (в том числе встроенное)
+func (ctx *parseContext) findAuthenticatorField(objectName string) (string, error) {
+	structs, err := ctx.packageStructs(ctx.pkg)
+	if err != nil {
+		return "", err
+	}
+
+	s, ok := (*structs)[objectName]
+	if !ok {
+		return "", nil
+	}
+
+	// Поле типа apigen.Authenticator, пакет мог быть импортирован под любым именем
+	for _, field := range *s.Fields {
+		selector, ok := field.StructType.(*ast.SelectorExpr)
+		if !ok || selector.Sel.Name != "Authenticator" {
+			continue
+		}
+		pkgIdent, ok := selector.X.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		importPath, err := ctx.loader.resolveImport(s.File, pkgIdent.Name, s.Package.Dir)
+		if err != nil {
+			return "", err
+		}
+		if importPath == *runtimeImport {
+			return field.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// Настройки из комментария apigen:handler к объявлению структуры обработчика
+func (ctx *parseContext) findHandlerSpecs(objectName string) (*HandlerSpecs, error) {
+	specs := &HandlerSpecs{}
+
+	structs, err := ctx.packageStructs(ctx.pkg)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := (*structs)[objectName]
+	if !ok {
+		return specs, nil
+	}
+
+	specsJson, err := findAnnotation(s.Doc, "apigen:handler ")
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid handler comment: %v", objectName, err)
+	}
+	if specsJson == "" {
+		return specs, nil
+	}
+	if err = json.Unmarshal([]byte(specsJson), specs); err != nil {
+		return nil, fmt.Errorf("%s: %v", objectName, err)
+	}
+	if err = specs.check(); err != nil {
+		return nil, fmt.Errorf("%s: %v", objectName, err)
+	}
+	return specs, nil
+}
+
+// Имя типа структуры в сгенерированном коде
+func (ctx *parseContext) qualify(s *dataStruct) (string, error) {
+	if s.Package.Dir == ctx.pkg.Dir {
+		return s.Name, nil
+	}
+	if err := ctx.data.Imports.add(s.Package.Name, s.Package.ImportPath); err != nil {
+		return "", err
+	}
+	return s.Package.Name + "." + s.Name, nil
+}
+
+func fieldParamName(field *dataStructField) string {
+	if field.Validator != nil && field.Validator.ParamName != "" {
+		return field.Validator.ParamName
+	}
+	return strings.ToLower(field.Name)
+}
+
+func joinParamName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// url метода с параметрами в пути: /user/{login}/profile, /user/{id:int}
+type urlRoute struct {
+	// Имя переменной с регулярным выражением в сгенерированном коде
+	Var     string
+	Pattern string
+	Params  []*routeParam
+	// Pattern по частям, для сравнения маршрутов между собой
+	tokens []routeToken
+}
+
+// Часть шаблона url: символ ('c'), необязательный символ ('?') или один и больше символов класса ('+')
+type routeToken struct {
+	kind  byte
+	char  byte
+	class string
+}
+
+// Классы символов для routeToken
+const (
+	notSlashClass = "notslash"
+	digitClass    = "digit"
+)
+
+type routeParam struct {
+	Name string
+	Type string
+	// Номер группы в регулярном выражении
+	Index int
+}
+
+// Чему должно соответствовать значение параметра каждого типа
+var routeParamPatterns = map[string]string{
+	"string": `[^/]+`,
+	"int":    `-?[0-9]+`,
+	"uint":   `[0-9]+`,
+}
+
+var routeParamNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.]*$`)
+
+// Разбирает url метода, для url без параметров возвращает nil
+func parseUrlRoute(url, varName string) (*urlRoute, error) {
+	if !strings.ContainsAny(url, "{}") {
+		return nil, nil
+	}
+
+	route := &urlRoute{Var: varName, Params: make([]*routeParam, 0)}
+	pattern := "^"
+	rest := url
+
+	for rest != "" {
+		open := strings.Index(rest, "{")
+		if closing := strings.Index(rest, "}"); closing != -1 && (open == -1 || closing < open) {
+			return nil, fmt.Errorf("invalid url %s: unexpected }", url)
+		}
+		if open == -1 {
+			pattern += regexp.QuoteMeta(rest)
+			route.addLiteral(rest)
+			break
+		}
+
+		pattern += regexp.QuoteMeta(rest[:open])
+		route.addLiteral(rest[:open])
+		rest = rest[open+1:]
+
+		closing := strings.Index(rest, "}")
+		if closing == -1 {
+			return nil, fmt.Errorf("invalid url %s: unclosed {", url)
+		}
+
+		param, err := parseRouteParam(rest[:closing])
+		if err != nil {
+			return nil, fmt.Errorf("invalid url %s: %v", url, err)
+		}
+		for _, existing := range route.Params {
+			if existing.Name == param.Name {
+				return nil, fmt.Errorf("invalid url %s: duplicate param %s", url, param.Name)
+			}
+		}
+
+		param.Index = len(route.Params) + 1
+		route.Params = append(route.Params, param)
+		pattern += "(" + routeParamPatterns[param.Type] + ")"
+		route.addParam(param.Type)
+		rest = rest[closing+1:]
+	}
+
+	route.Pattern = pattern + "$"
+	return route, nil
+}
+
+// {login} или {id:int}
+func parseRouteParam(placeholder string) (*routeParam, error) {
+	param := &routeParam{Name: placeholder, Type: "string"}
+
+	if index := strings.Index(placeholder, ":"); index != -1 {
+		param.Name = placeholder[:index]
+		param.Type = placeholder[index+1:]
+	}
+
+	if !routeParamNameRe.MatchString(param.Name) {
+		return nil, fmt.Errorf("invalid param name: {%s}", placeholder)
+	}
+	if _, ok := routeParamPatterns[param.Type]; !ok {
+		return nil, fmt.Errorf("unknown param type: {%s}", placeholder)
+	}
+
+	return param, nil
+}
+
+// Проверяет, что каждый параметр пути есть в структурах параметров метода и подходит по типу
+func (route *urlRoute) check(params []*dataStruct) error {
+	for _, routeParam := range route.Params {
+		var field *structParam
+		for _, s := range params {
+			for _, p := range *s.Params {
+				if p.ParamName == routeParam.Name {
+					field = p
+				}
+			}
+		}
+
+		if field == nil {
+			return fmt.Errorf("url param %s is not found in params struct", routeParam.Name)
+		}
+
+		if routeParam.Type != "string" {
+			switch field.Type {
+			case Int, Int64, Uint64, Float64, String:
+			default:
+				return fmt.Errorf("url param {%s:%s} can't be bound to %s field %s",
+					routeParam.Name, routeParam.Type, field.Type.GoType(), field.Path)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (route *urlRoute) addLiteral(literal string) {
+	for i := 0; i < len(literal); i++ {
+		route.tokens = append(route.tokens, routeToken{kind: 'c', char: literal[i]})
+	}
+}
+
+// Те же множества строк, что у routeParamPatterns
+func (route *urlRoute) addParam(paramType string) {
+	switch paramType {
+	case "string":
+		route.tokens = append(route.tokens, routeToken{kind: '+', class: notSlashClass})
+	case "int":
+		route.tokens = append(route.tokens, routeToken{kind: '?', char: '-'}, routeToken{kind: '+', class: digitClass})
+	case "uint":
+		route.tokens = append(route.tokens, routeToken{kind: '+', class: digitClass})
+	}
+}
+
+func (t routeToken) match(c byte) bool {
+	switch {
+	case t.kind != '+':
+		return c == t.char
+	case t.class == digitClass:
+		return c >= '0' && c <= '9'
+	default:
+		return c != '/'
+	}
+}
+
+// Состояния автомата по tokens: 2*i - перед i-й частью, 2*i+1 - внутри i-й части '+'.
+// Возвращает states, дополненные переходами без символа
+func (route *urlRoute) closure(states map[int]bool) map[int]bool {
+	res := make(map[int]bool, len(states))
+	queue := make([]int, 0, len(states))
+	for state := range states {
+		res[state] = true
+		queue = append(queue, state)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		i := state / 2
+		next := -1
+		if state%2 == 1 || i < len(route.tokens) && route.tokens[i].kind == '?' {
+			next = 2 * (i + 1)
+		}
+		if next != -1 && !res[next] {
+			res[next] = true
+			queue = append(queue, next)
+		}
+	}
+	return res
+}
+
+func (route *urlRoute) step(states map[int]bool, c byte) map[int]bool {
+	next := make(map[int]bool)
+	for state := range states {
+		i := state / 2
+		if i >= len(route.tokens) || !route.tokens[i].match(c) {
+			continue
+		}
+		if route.tokens[i].kind == '+' {
+			next[2*i+1] = true
+		} else {
+			next[2*(i+1)] = true
+		}
+	}
+	return route.closure(next)
+}
+
+func (route *urlRoute) accepts(states map[int]bool) bool {
+	return states[2*len(route.tokens)]
+}
+
+// Как маршрут other соотносится с route: есть ли путь, подходящий обоим, и есть ли путь,
+// подходящий только route или только other. Автоматы обоих url проходятся одновременно
+// по всем символам, которые в них различаются: символам url, цифрам, '-', '/' и любому другому
+func (route *urlRoute) compare(other *urlRoute) (common, onlyRoute, onlyOther bool) {
+	alphabet := []byte("0123456789-/\x00")
+	seen := make(map[byte]bool)
+	for _, c := range alphabet {
+		seen[c] = true
+	}
+	for _, r := range []*urlRoute{route, other} {
+		for _, t := range r.tokens {
+			if t.kind != '+' && !seen[t.char] {
+				seen[t.char] = true
+				alphabet = append(alphabet, t.char)
+			}
+		}
+	}
+
+	type pair struct{ a, b map[int]bool }
+	key := func(p pair) string {
+		return fmt.Sprint(sortedStates(p.a), sortedStates(p.b))
+	}
+	start := pair{route.closure(map[int]bool{0: true}), other.closure(map[int]bool{0: true})}
+	visited := map[string]bool{key(start): true}
+	queue := []pair{start}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		a, b := route.accepts(p.a), other.accepts(p.b)
+		common = common || a && b
+		onlyRoute = onlyRoute || a && !b
+		onlyOther = onlyOther || !a && b
+		for _, c := range alphabet {
+			next := pair{route.step(p.a, c), other.step(p.b, c)}
+			if len(next.a) == 0 && len(next.b) == 0 {
+				continue
+			}
+			if k := key(next); !visited[k] {
+				visited[k] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return common, onlyRoute, onlyOther
+}
+
+func sortedStates(states map[int]bool) []int {
+	res := make([]int, 0, len(states))
+	for state := range states {
+		res = append(res, state)
+	}
+	sort.Ints(res)
+	return res
+}
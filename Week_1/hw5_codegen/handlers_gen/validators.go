@@ -0,0 +1,130 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// Константа (или переменная для time.Time) с границей из min/max/minlen/maxlen
+type limitDecl struct {
+	Name string
+	// Пустой тип - переменная, тип выводится из значения
+	Type  string
+	Value string
+}
+
+func (l *limitDecl) IsVar() bool {
+	return l.Type == ""
+}
+
+// Проверка разобранного значения параметра: если Cond истинно, возвращается Error
+type valueCheck struct {
+	Cond  string
+	Error string
+}
+
+// Готовит для параметров структуры res проверки со всеми границами, вычисленными при генерации
+func buildValueChecks(res *dataStruct) {
+	res.Limits = make([]*limitDecl, 0)
+
+	for _, p := range *res.Params {
+		p.Checks = make([]*valueCheck, 0)
+		value := p.Var + "Val"
+		v := p.Validator
+
+		limitType := p.Type
+		lenPrefix := ""
+		if p.Type == String {
+			// для строк min/max ограничивают длину
+			limitType = Int
+			value = "len(" + value + ")"
+			lenPrefix = " len"
+		}
+
+		limits := []struct {
+			Name, Bound, Rule string
+			// Значение должно быть не меньше границы, иначе - не больше
+			IsMin bool
+		}{
+			{"min", v.Min, "min", true},
+			{"max", v.Max, "max", false},
+			{"minLen", v.MinLen, "minlen", true},
+			{"maxLen", v.MaxLen, "maxlen", false},
+		}
+
+		for _, limit := range limits {
+			if limit.Bound == "" {
+				continue
+			}
+
+			decl := &limitDecl{
+				Name:  limit.Name + res.Ident + p.Var,
+				Type:  limitType.GoType(),
+				Value: goLiteral(limitType, limit.Bound),
+			}
+			if limitType == Time {
+				decl.Type = ""
+			}
+			res.Limits = append(res.Limits, decl)
+
+			check := &valueCheck{}
+			if limit.IsMin {
+				check.Cond = limitType.Less(value, decl.Name)
+				check.Error = p.FieldError(limit.Rule, lenPrefix+" must be >= "+limit.Bound)
+			} else {
+				check.Cond = limitType.Greater(value, decl.Name)
+				check.Error = p.FieldError(limit.Rule, lenPrefix+" must be <= "+limit.Bound)
+			}
+			if limitType == Float64 {
+				check.Cond = floatLimitCond(value, decl.Name, limit.IsMin)
+			}
+			p.Checks = append(p.Checks, check)
+		}
+
+		if p.PatternVar != "" {
+			p.Checks = append(p.Checks, &valueCheck{
+				Cond:  "!" + p.PatternVar + ".MatchString(" + p.Var + "Val)",
+				Error: p.FieldError("pattern", " must match "+v.Pattern),
+			})
+		}
+	}
+}
+
+// Нарушение границы float64 записано через отрицание: сравнение с NaN всегда ложно, поэтому
+// NaN не проходит границу, даже если попал в значение в обход разбора параметра
+func floatLimitCond(value, bound string, isMin bool) string {
+	if isMin {
+		return "!(" + value + " >= " + bound + ")"
+	}
+	return "!(" + value + " <= " + bound + ")"
+}
+
+// Значение из тега как выражение Go. Значение уже проверено checkValue
+func goLiteral(t FieldTypeEnum, value string) string {
+	switch t {
+	case Int, Int64:
+		i, _ := strconv.ParseInt(value, 10, 64)
+		return strconv.FormatInt(i, 10)
+	case Uint64:
+		u, _ := strconv.ParseUint(value, 10, 64)
+		return strconv.FormatUint(u, 10)
+	case Float64:
+		f, _ := strconv.ParseFloat(value, 64)
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	case Time:
+		t, _ := time.Parse(time.RFC3339, value)
+		return "time.Unix(" + strconv.FormatInt(t.Unix(), 10) + ", " + strconv.Itoa(t.Nanosecond()) + ")"
+	case Duration:
+		d, _ := time.ParseDuration(value)
+		return strconv.FormatInt(int64(d), 10)
+	default:
+		return strconv.Quote(value)
+	}
+}
+
+// Для границ float64 годятся только конечные числа: +Inf не записать литералом Go
+func isFiniteFloat(value string) bool {
+	f, err := strconv.ParseFloat(value, 64)
+	return err == nil && !math.IsInf(f, 0) && !math.IsNaN(f)
+}